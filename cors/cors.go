@@ -1,16 +1,34 @@
 package cors
 
 import (
+	"net/http"
 	"regexp"
 	"strings"
+	"sync"
+
+	"goa.design/plugins/v3/internal/vary"
 )
 
+// regexps caches the regular expressions compiled by MatchOrigin indexed by
+// origin specification.
+var regexps sync.Map
+
 // MatchOrigin returns true if the given Origin header value matches the
 // origin specification.
 // Spec can be one of:
-// - a plain string identifying an origin. eg http://swagger.goa.design
-// - a plain string containing a wildcard. eg *.goa.design
-// - the special string * that matches every host
+//   - a plain string identifying an origin. eg http://swagger.goa.design
+//   - an origin with wildcards. eg https://*.goa.design, https://*.*.goa.design
+//     or http://localhost:*
+//   - a plain string without scheme containing a wildcard. eg *.goa.design
+//   - the special string * that matches every host
+//   - a regular expression wrapped with "/". eg /.*goa[.]design/
+//
+// Wildcards in origins with a scheme are matched label by label: the scheme
+// must be identical, a "*" in a host label matches characters within that
+// label only (so https://*.goa.design does not match
+// https://evil.com.goa.design) and a "*" port matches any port. Wildcards in
+// specifications without a scheme match any sequence of characters.
+// Regular expressions are compiled once and cached.
 func MatchOrigin(origin, spec string) bool {
 	if spec == "*" {
 		return true
@@ -18,14 +36,19 @@ func MatchOrigin(origin, spec string) bool {
 
 	// Check regular expression
 	if strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/") {
-		stripped := strings.Trim(spec, "/")
-		r := regexp.MustCompile(stripped)
-		return MatchOriginRegexp(origin, r)
+		r, ok := regexps.Load(spec)
+		if !ok {
+			r, _ = regexps.LoadOrStore(spec, regexp.MustCompile(strings.Trim(spec, "/")))
+		}
+		return MatchOriginRegexp(origin, r.(*regexp.Regexp))
 	}
 
 	if !strings.Contains(spec, "*") {
 		return origin == spec
 	}
+	if p, ok := parseOriginPattern(spec); ok {
+		return p.matchOrigin(origin)
+	}
 	parts := strings.SplitN(spec, "*", 2)
 	if !strings.HasPrefix(origin, parts[0]) {
 		return false
@@ -42,3 +65,57 @@ func MatchOrigin(origin, spec string) bool {
 func MatchOriginRegexp(origin string, spec *regexp.Regexp) bool {
 	return spec.Match([]byte(origin))
 }
+
+// MatchMethod returns true if the given HTTP method is one of the allowed
+// methods. The special value "*" allows all methods. If allowed is empty then
+// only the CORS-safelisted methods GET, HEAD and POST match.
+func MatchMethod(method string, allowed ...string) bool {
+	if len(allowed) == 0 {
+		allowed = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	for _, m := range allowed {
+		if m == "*" || m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchHeaders returns true if all the headers listed in the given
+// Access-Control-Request-Headers header value are allowed. The special value
+// "*" allows all headers. CORS-safelisted request headers are always allowed.
+func MatchHeaders(headers string, allowed ...string) bool {
+	for _, h := range strings.Split(headers, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" || isSafelistedHeader(h) {
+			continue
+		}
+		found := false
+		for _, a := range allowed {
+			if a == "*" || strings.EqualFold(a, h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AddVary adds the given header names to the Vary header of h unless they are
+// already listed. AddVary preserves the existing values so that the Vary
+// header set by other handlers or middlewares is not overwritten.
+func AddVary(h http.Header, names ...string) {
+	vary.Add(h, names...)
+}
+
+// isSafelistedHeader returns true if h is a CORS-safelisted request header.
+func isSafelistedHeader(h string) bool {
+	switch http.CanonicalHeaderKey(h) {
+	case "Accept", "Accept-Language", "Content-Language", "Content-Type":
+		return true
+	}
+	return false
+}
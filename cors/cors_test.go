@@ -1,6 +1,9 @@
 package cors
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -26,6 +29,38 @@ func TestMatchOrigin(t *testing.T) {
 				{"some.domain.com", false},
 			},
 		},
+		"scheme-wildcard-spec": {
+			"https://*.goa.design": {
+				{"https://swagger.goa.design", true},
+				{"https://SWAGGER.goa.design", true},
+				{"https://evil.com.goa.design", false},
+				{"http://swagger.goa.design", false},
+				{"https://swagger.goa.design:8080", false},
+				{"https://.goa.design", false},
+			},
+			"https://*.*.goa.design": {
+				{"https://v1.api.goa.design", true},
+				{"https://api.goa.design", false},
+			},
+			"https://api-*.goa.design": {
+				{"https://api-v1.goa.design", true},
+				{"https://web-v1.goa.design", false},
+			},
+			"https://*": {
+				{"https://goa.design", true},
+				{"https://api.example.com", true},
+				{"http://api.example.com", false},
+			},
+			"http://localhost:*": {
+				{"http://localhost:3000", true},
+				{"http://localhost", true},
+				{"http://localhost.evil.com:3000", false},
+			},
+			"http://[::1]:*": {
+				{"http://[::1]:3000", true},
+				{"http://[::2]:3000", false},
+			},
+		},
 		"regex-spec": {
 			"/.*domain\\..+/": {
 				{"some.domain.com", true},
@@ -46,3 +81,199 @@ func TestMatchOrigin(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchMethod(t *testing.T) {
+	cases := []struct {
+		name    string
+		method  string
+		allowed []string
+		output  bool
+	}{
+		{"allowed", "PUT", []string{"GET", "PUT"}, true},
+		{"not-allowed", "DELETE", []string{"GET", "PUT"}, false},
+		{"safelisted", "POST", nil, true},
+		{"not-safelisted", "PUT", nil, false},
+		{"wildcard", "DELETE", []string{"*"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := MatchMethod(tc.method, tc.allowed...)
+			if output != tc.output {
+				t.Errorf("MatchMethod(%q, %v): Expected %t, Got %t", tc.method, tc.allowed, tc.output, output)
+			}
+		})
+	}
+}
+
+func TestMatchMethodStrict(t *testing.T) {
+	h := Handler(Policy{Origin: "http://goa.design", Methods: []string{"*"}, Strict: true})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }))
+	cases := []struct {
+		name   string
+		method string
+		acrm   string
+		status int
+	}{
+		{"preflight", "OPTIONS", "DELETE", http.StatusOK},
+		{"actual", "DELETE", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			r.Header.Set("Origin", "http://goa.design")
+			if tc.acrm != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.acrm)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://goa.design" {
+				t.Errorf("got Access-Control-Allow-Origin %q, expected %q", got, "http://goa.design")
+			}
+		})
+	}
+}
+
+func TestMatchHeaders(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers string
+		allowed []string
+		output  bool
+	}{
+		{"empty", "", nil, true},
+		{"allowed", "x-shared-secret, x-api-version", []string{"X-Shared-Secret", "X-Api-Version"}, true},
+		{"not-allowed", "x-shared-secret, x-other", []string{"X-Shared-Secret"}, false},
+		{"wildcard", "x-any", []string{"*"}, true},
+		{"safelisted", "content-type, accept", nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := MatchHeaders(tc.headers, tc.allowed...)
+			if output != tc.output {
+				t.Errorf("MatchHeaders(%q, %v): Expected %t, Got %t", tc.headers, tc.allowed, tc.output, output)
+			}
+		})
+	}
+}
+
+func TestAddVary(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing []string
+		names    []string
+		expected []string
+	}{
+		{"empty", nil, []string{"Origin"}, []string{"Origin"}},
+		{"merge", []string{"Accept-Encoding"}, []string{"Origin"}, []string{"Accept-Encoding", "Origin"}},
+		{"already-listed", []string{"accept-encoding, origin"}, []string{"Origin"}, []string{"accept-encoding, origin"}},
+		{"partially-listed", []string{"Origin"}, []string{"Origin", "Access-Control-Request-Method"}, []string{"Origin", "Access-Control-Request-Method"}},
+		{"wildcard", []string{"*"}, []string{"Origin"}, []string{"*"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := make(http.Header)
+			for _, v := range tc.existing {
+				h.Add("Vary", v)
+			}
+			AddVary(h, tc.names...)
+			got := h.Values("Vary")
+			if len(got) != len(tc.expected) {
+				t.Fatalf("got Vary %v, expected %v", got, tc.expected)
+			}
+			for i, v := range tc.expected {
+				if got[i] != v {
+					t.Errorf("got Vary %v, expected %v", got, tc.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestOriginMatcher(t *testing.T) {
+	origins := []string{
+		"http://exact.goa.design",
+		"https://*.api.goa.design",
+		"/http://regexp[.]goa[.]design/",
+		"https://*.goa.design",
+		"http://pre*post",
+		"http://exact.goa.design",
+		"http://*.*.goa.design:*",
+		"https://*",
+		"*",
+	}
+	cases := []struct {
+		origin string
+		index  int
+	}{
+		{"http://exact.goa.design", 0},
+		{"https://v1.api.goa.design", 1},
+		{"http://regexp.goa.design", 2},
+		{"https://swagger.goa.design", 3},
+		{"https://v1.api.other.design", 7},
+		{"https://evil.com.goa.design", 7},
+		{"http://v1.api.goa.design:8080", 6},
+		{"http://pre-and-post", 4},
+		{"http://other.design", 8},
+	}
+	m := newOriginMatcher(origins)
+	for _, tc := range cases {
+		if got := m.match(tc.origin); got != tc.index {
+			t.Errorf("match(%q): got %d, expected %d", tc.origin, got, tc.index)
+		}
+		expected := -1
+		for i, o := range origins {
+			if MatchOrigin(tc.origin, o) {
+				expected = i
+				break
+			}
+		}
+		if expected != tc.index {
+			t.Errorf("MatchOrigin(%q): got %d, expected %d", tc.origin, expected, tc.index)
+		}
+	}
+	if got := newOriginMatcher(origins[:5]).match("http://other.design"); got != -1 {
+		t.Errorf("match(%q): got %d, expected -1", "http://other.design", got)
+	}
+}
+
+// tenantOrigins returns n origins mixing exact, wildcard and regular
+// expression specifications.
+func tenantOrigins(n int) []string {
+	origins := make([]string, n)
+	for i := range origins {
+		switch {
+		case i%50 == 49:
+			origins[i] = fmt.Sprintf("/https://tenant%d[.]goa[.]io/", i)
+		case i%2 == 0:
+			origins[i] = fmt.Sprintf("https://tenant%d.goa.design", i)
+		default:
+			origins[i] = fmt.Sprintf("https://*.tenant%d.goa.design", i)
+		}
+	}
+	return origins
+}
+
+func BenchmarkMatchOrigin(b *testing.B) {
+	origins := tenantOrigins(300)
+	origin := "https://app.tenant297.goa.design"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, o := range origins {
+			if MatchOrigin(origin, o) {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkOriginMatcher(b *testing.B) {
+	m := newOriginMatcher(tenantOrigins(300))
+	origin := "https://app.tenant297.goa.design"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.match(origin)
+	}
+}
@@ -11,12 +11,18 @@ import (
 	_ "goa.design/plugins/v3/cors"
 )
 
-// Origin defines the CORS policy for a given origin. The origin can use a wildcard prefix
-// such as "https://*.mydomain.com". The special value "*" defines the policy for all origins
-// (in which case there should be only one Origin DSL in the parent resource).
-// The origin can also be a regular expression in which case it must be wrapped with "/".
+// Origin defines the CORS policy for a given origin. The origin can use wildcards in the host
+// labels such as "https://*.mydomain.com" or "https://*.*.mydomain.com" and in the port such
+// as "http://localhost:*". A wildcard in a host label only matches characters in that label so
+// that "https://*.mydomain.com" does not match "https://evil.com.mydomain.com", a host made
+// only of a wildcard such as "https://*" matches any host using the scheme. The special
+// value "*" defines the policy for all origins (in which case there should be only one Origin
+// DSL in the parent resource). The origin can also be a regular expression in which case it
+// must be wrapped with "/".
 //
-// Origin must appear in API or Service Expression.
+// Origin must appear in API, Service, Method or HTTP (inside Method)
+// expression. Origins defined at the method level replace the API and service
+// level origins for the method endpoints.
 //
 // Origin accepts an origin string as the first argument and
 // an optional DSL function as the second argument.
@@ -32,6 +38,9 @@ import (
 //            cors.Expose("X-Time")            // One or more headers exposed to clients
 //            cors.MaxAge(600)                 // How long to cache a preflight request response
 //            cors.Credentials()               // Sets Access-Control-Allow-Credentials header
+//            cors.AllowPrivateNetwork()       // Sets Access-Control-Allow-Private-Network header
+//            cors.Strict()                    // Rejects preflight requests for unauthorized methods or headers
+//            cors.GRPCWeb()                   // Authorizes gRPC-Web clients
 //        })
 //    })
 //
@@ -43,6 +52,10 @@ import (
 //            Payload(Operands)
 //            Error(ErrBadRequest, ErrorResult)
 //        })
+//
+//        Method("reset", func() {
+//            cors.Origin("https://admin.goa.design") // Restrict CORS policy to a single origin for this method
+//        })
 //    })
 //
 func Origin(origin string, args ...interface{}) {
@@ -51,7 +64,43 @@ func Origin(origin string, args ...interface{}) {
 		o.Regexp = true
 		o.Origin = strings.Trim(origin, "/")
 	}
+	defineOrigin(origin, o, args)
+}
 
+// OriginFunc defines the CORS policy for the origins authorized by a Go
+// function. The function is identified by its package import path and name,
+// e.g. "github.com/acme/tenants.IsAllowedOrigin", and must have the signature:
+//
+//     func(r *http.Request) bool
+//
+// The generated origin handlers import the package and call the function with
+// the request to decide whether the request origin is authorized. The policies
+// that apply to a service or method are evaluated in the lexical order of the
+// strings given to Origin and OriginFunc (the function is identified by its
+// import path and name) and the function is only called if no policy evaluated
+// before it matches the origin. The settings defined in the optional DSL
+// function (methods, headers, max age etc.) apply to the origins authorized by
+// the function.
+//
+// OriginFunc must appear in API, Service, Method or HTTP (inside Method)
+// expression.
+//
+// Example:
+//
+//    var _ = Service("calculator", func() {
+//        cors.OriginFunc("github.com/acme/tenants.IsAllowedOrigin", func() {
+//            cors.Methods("GET", "POST")
+//            cors.MaxAge(600)
+//        })
+//    })
+//
+func OriginFunc(fn string, args ...interface{}) {
+	defineOrigin(fn, &expr.OriginExpr{Origin: fn, Func: true}, args)
+}
+
+// defineOrigin executes the DSL given in args and records the origin
+// expression in the current API, service or method.
+func defineOrigin(origin string, o *expr.OriginExpr, args []interface{}) {
 	var dsl func()
 	{
 		if len(args) > 0 {
@@ -68,22 +117,60 @@ func Origin(origin string, args ...interface{}) {
 	}
 
 	current := eval.Current()
-	switch current.(type) {
+	switch actual := current.(type) {
 	case *goaexpr.APIExpr:
-		expr.Root.APIOrigins[origin] = o
+		addOrigin(expr.Root.APIOrigins, current, origin, o)
 	case *goaexpr.ServiceExpr:
 		{
-			s := current.(*goaexpr.ServiceExpr).Name
+			s := actual.Name
 			if _, ok := expr.Root.ServiceOrigins[s]; !ok {
 				expr.Root.ServiceOrigins[s] = make(map[string]*expr.OriginExpr)
 			}
-			expr.Root.ServiceOrigins[s][origin] = o
+			addOrigin(expr.Root.ServiceOrigins[s], current, origin, o)
 		}
+	case *goaexpr.MethodExpr:
+		addMethodOrigin(actual, current, origin, o)
+	case *goaexpr.HTTPEndpointExpr:
+		addMethodOrigin(actual.MethodExpr, current, origin, o)
 	default:
 		eval.IncompatibleDSL()
 		return
 	}
-	o.Parent = current
+}
+
+// addMethodOrigin records the origin expression o defined in parent, the given
+// method or its HTTP endpoint.
+func addMethodOrigin(m *goaexpr.MethodExpr, parent eval.Expression, origin string, o *expr.OriginExpr) {
+	s := m.Service.Name
+	if _, ok := expr.Root.MethodOrigins[s]; !ok {
+		expr.Root.MethodOrigins[s] = make(map[string]map[string]*expr.OriginExpr)
+	}
+	if _, ok := expr.Root.MethodOrigins[s][m.Name]; !ok {
+		expr.Root.MethodOrigins[s][m.Name] = make(map[string]*expr.OriginExpr)
+	}
+	addOrigin(expr.Root.MethodOrigins[s][m.Name], parent, origin, o)
+}
+
+// addOrigin records the origin expression o defined in parent in origins. It
+// reports an error if the scope of parent already defines the origin with a
+// different policy.
+func addOrigin(origins map[string]*expr.OriginExpr, parent eval.Expression, origin string, o *expr.OriginExpr) {
+	if prev, ok := origins[origin]; ok && scope(prev.Parent) == scope(parent) && !prev.SamePolicy(o) {
+		// ReportError appends the name of the current expression.
+		eval.ReportError("origin %q is already defined with a different policy", origin)
+		return
+	}
+	o.Parent = parent
+	origins[origin] = o
+}
+
+// scope returns the expression defining the scope of the origins defined in
+// parent: the method of HTTP endpoints and parent otherwise.
+func scope(parent eval.Expression) eval.Expression {
+	if e, ok := parent.(*goaexpr.HTTPEndpointExpr); ok {
+		return e.MethodExpr
+	}
+	return parent
 }
 
 // Methods sets the origin allowed methods.
@@ -184,3 +271,111 @@ func Credentials() {
 		eval.IncompatibleDSL()
 	}
 }
+
+// AllowPrivateNetwork sets the allow private network response header in
+// response to Private Network Access preflight requests, that is preflight
+// requests made by browsers with the Access-Control-Request-Private-Network
+// header to access servers on a private network from a public website.
+//
+// AllowPrivateNetwork must be used in an Origin expression.
+//
+// Example:
+//
+//     Origin("https://dashboard.goa.design", func() {
+//         AllowPrivateNetwork()    // Sets Access-Control-Allow-Private-Network header
+//     })
+//
+func AllowPrivateNetwork() {
+	switch o := eval.Current().(type) {
+	case *expr.OriginExpr:
+		o.PrivateNetwork = true
+	default:
+		eval.IncompatibleDSL()
+	}
+}
+
+// Strict enables the validation of preflight requests against the origin
+// authorized methods and headers. Preflight requests for methods not listed in
+// Methods (or not CORS-safelisted if Methods is not used) or for headers not
+// listed in Headers are rejected with a 403 Forbidden response. Preflight
+// requests with an origin that does not match any policy are also rejected
+// when at least one of the policies is strict. Finally the CORS headers are
+// not set in the responses to actual requests made with a method that is not
+// authorized.
+//
+// Strict must be used in an Origin expression.
+//
+// Example:
+//
+//     Origin("http://swagger.goa.design", func() {
+//         Methods("GET", "POST")
+//         Headers("X-Shared-Secret")
+//         Strict()                 // Rejects preflight requests for DELETE or X-Other
+//     })
+//
+func Strict() {
+	switch o := eval.Current().(type) {
+	case *expr.OriginExpr:
+		o.Strict = true
+	default:
+		eval.IncompatibleDSL()
+	}
+}
+
+// GRPCWeb authorizes gRPC-Web clients running on the origin. It adds the
+// headers sent by gRPC-Web clients (Content-Type, X-Grpc-Web, X-User-Agent and
+// Grpc-Timeout) to the authorized headers, the POST method to the authorized
+// methods if any and the gRPC status headers to the exposed headers.
+//
+// GRPCWeb also causes the generation of the Handle<Service>Origin function in
+// the gRPC server package of the service. The function applies the service
+// CORS policies and is meant to wrap the gRPC-Web handler (e.g. created with
+// github.com/improbable-eng/grpc-web) that serves the gRPC server. Origins
+// defined on gRPC methods are not taken into account by this handler.
+//
+// GRPCWeb must be used in an Origin expression.
+//
+// Example:
+//
+//     Origin("https://app.goa.design", func() {
+//         GRPCWeb()    // Authorizes gRPC-Web clients
+//     })
+//
+func GRPCWeb() {
+	switch o := eval.Current().(type) {
+	case *expr.OriginExpr:
+		o.GRPCWeb = true
+	default:
+		eval.IncompatibleDSL()
+	}
+}
+
+// GenerateTests enables the generation of a cors_test.go file next to the
+// HTTP server code of the services. The file tests the origin handlers of the
+// services against each origin defined in the design: it checks that requests
+// from a matching origin get the CORS headers (including the credentials
+// header), that requests from a non-matching origin do not and that preflight
+// requests for each authorized method succeed. Regular expression origins are
+// tested with a generated matching origin, the tests of origin functions are
+// skipped.
+//
+// GenerateTests must appear in an API or Service expression. When used in an
+// API expression the tests are generated for all the services.
+//
+// Example:
+//
+//     var _ = Service("calc", func() {
+//         GenerateTests()    // Generates gen/http/calc/server/cors_test.go
+//         Origin("https://calc.goa.design")
+//     })
+//
+func GenerateTests() {
+	switch actual := eval.Current().(type) {
+	case *goaexpr.APIExpr:
+		expr.Root.Tests[""] = true
+	case *goaexpr.ServiceExpr:
+		expr.Root.Tests[actual.Name] = true
+	default:
+		eval.IncompatibleDSL()
+	}
+}
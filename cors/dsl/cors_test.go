@@ -0,0 +1,62 @@
+package dsl_test
+
+import (
+	"strings"
+	"testing"
+
+	. "goa.design/goa/v3/dsl"
+	"goa.design/goa/v3/expr"
+	cors "goa.design/plugins/v3/cors/dsl"
+)
+
+func TestOriginRedefined(t *testing.T) {
+	cases := []struct {
+		name  string
+		dsl   func()
+		error string
+	}{
+		{"api", func() {
+			API("calc", func() {
+				cors.Origin("https://goa.design", func() { cors.Methods("GET") })
+				cors.Origin("https://goa.design", func() { cors.Methods("POST") })
+			})
+		}, `origin "https://goa.design" is already defined with a different policy in API calc`},
+		{"service", func() {
+			Service("calc", func() {
+				cors.Origin("https://goa.design", func() { cors.MaxAge(60) })
+				cors.Origin("https://goa.design", func() { cors.Credentials() })
+			})
+		}, `origin "https://goa.design" is already defined with a different policy in service "calc"`},
+		{"method", func() {
+			Service("calc", func() {
+				Method("add", func() {
+					cors.Origin("https://goa.design", func() { cors.Headers("X-Time") })
+					HTTP(func() {
+						GET("/")
+						cors.Origin("https://goa.design")
+					})
+				})
+			})
+		}, `origin "https://goa.design" is already defined with a different policy in service "calc" HTTP endpoint "add"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := expr.RunInvalidDSL(t, c.dsl)
+			if err == nil {
+				t.Fatal("got no error, expected an error")
+			}
+			if !strings.Contains(err.Error(), c.error) {
+				t.Errorf("got error %q, expected to contain %q", err.Error(), c.error)
+			}
+		})
+	}
+}
+
+func TestOriginRedefinedSamePolicy(t *testing.T) {
+	expr.RunDSL(t, func() {
+		Service("calc", func() {
+			cors.Origin("https://goa.design", func() { cors.Methods("GET") })
+			cors.Origin("https://goa.design", func() { cors.Methods("GET") })
+		})
+	})
+}
@@ -2,9 +2,12 @@ package calc
 
 import (
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	goahttp "goa.design/goa/v3/http"
+	"goa.design/plugins/v3/cors"
 	"goa.design/plugins/v3/cors/examples/calc/gen/calc"
 	calcserver "goa.design/plugins/v3/cors/examples/calc/gen/http/calc/server"
 )
@@ -15,3 +18,60 @@ func TestMountIndexHTML(t *testing.T) {
 	s := calcserver.New(e, mux, nil, nil, nil, nil, nil)
 	calcserver.Mount(mux, s)
 }
+
+func TestPolicyProvider(t *testing.T) {
+	var store cors.PolicyStore
+	if err := store.StoreMethod("add", cors.Policy{Origin: "https://admin.goa.design"}); err != nil {
+		t.Fatal(err)
+	}
+	e := calc.NewEndpoints(NewCalc(log.Default()))
+	cases := []struct {
+		name   string
+		opts   []cors.Option
+		origin string
+	}{
+		{"provider", []cors.Option{cors.WithPolicyProvider(&store)}, "https://admin.goa.design"},
+		{"design", nil, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mux := goahttp.NewMuxer()
+			s := calcserver.New(e, mux, goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil, nil, c.opts...)
+			calcserver.Mount(mux, s)
+			r := httptest.NewRequest("GET", "/add/1/2", nil)
+			r.Header.Set("Origin", "https://admin.goa.design")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Errorf("got status %d, expected %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != c.origin {
+				t.Errorf("got Access-Control-Allow-Origin %q, expected %q", got, c.origin)
+			}
+		})
+	}
+}
+
+func TestObserver(t *testing.T) {
+	e := calc.NewEndpoints(NewCalc(log.Default()))
+	observed := make([]string, 2)
+	muxes := make([]goahttp.Muxer, 2)
+	for i := range muxes {
+		i := i
+		obs := cors.ObserverFunc(func(r *http.Request, origin string, p *cors.Policy, preflight bool) {
+			observed[i] = origin
+		})
+		muxes[i] = goahttp.NewMuxer()
+		s := calcserver.New(e, muxes[i], goahttp.RequestDecoder, goahttp.ResponseEncoder, nil, nil, nil, cors.WithObserver(obs))
+		calcserver.Mount(muxes[i], s)
+	}
+	r := httptest.NewRequest("GET", "/add/1/2", nil)
+	r.Header.Set("Origin", "https://other.goa.design")
+	muxes[1].ServeHTTP(httptest.NewRecorder(), r)
+	if observed[0] != "" {
+		t.Errorf("got origin %q observed by the first server, expected none", observed[0])
+	}
+	if observed[1] != "https://other.goa.design" {
+		t.Errorf("got origin %q observed by the second server, expected %q", observed[1], "https://other.goa.design")
+	}
+}
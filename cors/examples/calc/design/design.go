@@ -19,6 +19,7 @@ var _ = API("calc", func() {
 
 var _ = Service("calc", func() {
 	Description("The calc service exposes public endpoints that defines CORS policy.")
+	cors.GenerateTests()
 	cors.Origin("/.*localhost.*/", func() {
 		cors.Methods("GET", "POST")
 		cors.Expose("X-Time", "X-Api-Version")
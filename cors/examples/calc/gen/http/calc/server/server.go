@@ -11,7 +11,6 @@ package server
 import (
 	"context"
 	"net/http"
-	"regexp"
 
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
@@ -25,6 +24,9 @@ type Server struct {
 	Add       http.Handler
 	CORS      http.Handler
 	IndexHTML http.Handler
+
+	// corsOptions configures the CORS origin handlers, see New.
+	corsOptions []cors.Option
 }
 
 // ErrorNamer is an interface implemented by generated error structs that
@@ -58,6 +60,7 @@ func New(
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
 	fileSystemIndexHTML http.FileSystem,
+	corsOpts ...cors.Option,
 ) *Server {
 	if fileSystemIndexHTML == nil {
 		fileSystemIndexHTML = http.Dir(".")
@@ -69,9 +72,10 @@ func New(
 			{"CORS", "OPTIONS", "/"},
 			{"/index.html", "GET", "/"},
 		},
-		Add:       NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
-		CORS:      NewCORSHandler(),
-		IndexHTML: http.FileServer(fileSystemIndexHTML),
+		Add:         NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
+		CORS:        NewCORSHandler(),
+		IndexHTML:   HandleCalcFilesOrigin(http.FileServer(fileSystemIndexHTML), corsOpts...),
+		corsOptions: corsOpts,
 	}
 }
 
@@ -86,8 +90,8 @@ func (s *Server) Use(m func(http.Handler) http.Handler) {
 
 // Mount configures the mux to serve the calc endpoints.
 func Mount(mux goahttp.Muxer, h *Server) {
-	MountAddHandler(mux, h.Add)
-	MountCORSHandler(mux, h.CORS)
+	MountAddHandler(mux, h.Add, h.corsOptions...)
+	MountCORSHandler(mux, h.CORS, h.corsOptions...)
 	MountIndexHTML(mux, h.IndexHTML)
 }
 
@@ -98,8 +102,8 @@ func (s *Server) Mount(mux goahttp.Muxer) {
 
 // MountAddHandler configures the mux to serve the "calc" service "add"
 // endpoint.
-func MountAddHandler(mux goahttp.Muxer, h http.Handler) {
-	f, ok := HandleCalcOrigin(h).(http.HandlerFunc)
+func MountAddHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	f, ok := HandleCalcAddOrigin(h, opts...).(http.HandlerFunc)
 	if !ok {
 		f = func(w http.ResponseWriter, r *http.Request) {
 			h.ServeHTTP(w, r)
@@ -149,15 +153,16 @@ func NewAddHandler(
 
 // MountIndexHTML configures the mux to serve GET request made to "/".
 func MountIndexHTML(mux goahttp.Muxer, h http.Handler) {
-	mux.Handle("GET", "/", HandleCalcOrigin(h).ServeHTTP)
+	mux.Handle("GET", "/", h.ServeHTTP)
 }
 
 // MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service calc.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleCalcOrigin(h)
-	mux.Handle("OPTIONS", "/add/{a}/{b}", h.ServeHTTP)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service calc. The origin handlers are configured with the given options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hAdd := HandleCalcAddOrigin(h, opts...)
+	hFiles := HandleCalcFilesOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/add/{a}/{b}", hAdd.ServeHTTP)
+	mux.Handle("OPTIONS", "/", hFiles.ServeHTTP)
 }
 
 // NewCORSHandler creates a HTTP handler which returns a simple 200 response.
@@ -169,42 +174,40 @@ func NewCORSHandler() http.Handler {
 
 // HandleCalcOrigin applies the CORS response headers corresponding to the
 // origin for the service calc.
-func HandleCalcOrigin(h http.Handler) http.Handler {
-	spec0 := regexp.MustCompile(".*localhost.*")
+func HandleCalcOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("calc", "", []cors.Policy{
+		{
+			Origin:  "/.*localhost.*/",
+			Methods: []string{"GET", "POST"},
+			Exposed: []string{"X-Time", "X-Api-Version"},
+			MaxAge:  100,
+		},
+		{
+			Origin:      "http://127.0.0.1",
+			Methods:     []string{"GET", "POST"},
+			Exposed:     []string{"X-Time"},
+			Headers:     []string{"X-Shared-Secret"},
+			MaxAge:      600,
+			Credentials: true,
+		},
+	}, opts...)(h)
+}
+
+// HandleCalcFilesOrigin applies the CORS response headers corresponding to the
+// origin for the file servers of the service calc. It authorizes the
+// conditional and range requests and exposes the headers of the partial and
+// cached content responses.
+func HandleCalcFilesOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	h = HandleCalcOrigin(h, opts...)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOriginRegexp(origin, spec0) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Time, X-Api-Version")
-			w.Header().Set("Access-Control-Max-Age", "100")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "http://127.0.0.1") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Time")
-			w.Header().Set("Access-Control-Max-Age", "600")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-				w.Header().Set("Access-Control-Allow-Headers", "X-Shared-Secret")
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
+		h.ServeHTTP(w, r.WithContext(cors.WithFileServer(r.Context())))
 	})
 }
+
+// HandleCalcAddOrigin applies the CORS response headers corresponding to the
+// origin for the method add of the service calc. The method uses the CORS
+// policies of the service unless the policy provider returns policies for the
+// method.
+func HandleCalcAddOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.MethodHandler("calc", "add", HandleCalcOrigin, opts...)(h)
+}
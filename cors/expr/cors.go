@@ -2,6 +2,7 @@ package expr
 
 import (
 	"fmt"
+	"go/token"
 	"regexp"
 	"sort"
 	"strings"
@@ -26,9 +27,23 @@ type (
 		// Credentials sets Access-Control-Allow-Credentials header in the
 		// response.
 		Credentials bool
+		// PrivateNetwork sets the Access-Control-Allow-Private-Network header
+		// in the response to Private Network Access preflight requests.
+		PrivateNetwork bool
+		// Strict rejects preflight requests for methods or headers that are
+		// not authorized by the policy and omits the CORS headers from the
+		// responses to requests made with unauthorized methods.
+		Strict bool
+		// GRPCWeb authorizes the headers used by gRPC-Web clients and
+		// generates the CORS handler of the gRPC server.
+		GRPCWeb bool
+		// Func tells whether the Origin string identifies a Go function
+		// authorizing origins, see FuncImport.
+		Func bool
 		// Regexp tells whether the Origin string is a regular expression.
 		Regexp bool
-		// Parent expression, ServiceExpr or APIExpr.
+		// Parent expression, one of APIExpr, ServiceExpr, MethodExpr or
+		// HTTPEndpointExpr.
 		Parent eval.Expression
 	}
 )
@@ -50,16 +65,61 @@ func Origins(svc string) []*OriginExpr {
 			origins[n] = o
 		}
 	}
-	names := make([]string, 0, len(origins))
-	for n := range origins {
-		names = append(names, n)
+	return sortOrigins(origins)
+}
+
+// GenerateTests returns true if the CORS tests of the given service must be
+// generated.
+func GenerateTests(svc string) bool {
+	return Root.Tests[""] || Root.Tests[svc]
+}
+
+// MethodOrigins returns the origin expressions (sorted alphabetically by
+// origin string) defined at the method level for the given service method.
+// Method level origins replace the service and API level origins entirely so
+// that a method may restrict access to a subset of the origins authorized by
+// the service. MethodOrigins returns nil if the method does not define any
+// origin.
+func MethodOrigins(svc, method string) []*OriginExpr {
+	mo, ok := Root.MethodOrigins[svc]
+	if !ok {
+		return nil
 	}
-	sort.Strings(names)
-	oexps := make([]*OriginExpr, 0, len(names))
-	for _, n := range names {
-		oexps = append(oexps, origins[n])
+	origins, ok := mo[method]
+	if !ok || len(origins) == 0 {
+		return nil
 	}
-	return oexps
+	return sortOrigins(origins)
+}
+
+// FileServerPaths returns the paths served by the file servers of the given
+// service. The paths of the file servers serving directories include both the
+// directory path and the wildcard path matching the files in the directory
+// (e.g. "/static/" and "/static/{*filepath}").
+func FileServerPaths(svc string) []string {
+	var paths []string
+	s := expr.Root.API.HTTP.Service(svc)
+	if s == nil {
+		return paths
+	}
+	for _, fs := range s.FileServers {
+		for _, fp := range fs.RequestPaths {
+			// IsDir only looks at the first request path, check each path
+			// for a wildcard.
+			wildcards := expr.ExtractHTTPWildcards(fp)
+			if !fs.IsDir() || len(wildcards) == 0 {
+				paths = append(paths, fp)
+				continue
+			}
+			dir := fp
+			if i := strings.LastIndex(fp, "/{"); i >= 0 {
+				dir = fp[:i]
+			}
+			dir = strings.TrimSuffix(dir, "/")
+			paths = append(paths, dir+"/", dir+"/{*"+wildcards[0]+"}")
+		}
+	}
+	return paths
 }
 
 // PreflightPaths returns the paths that should handle OPTIONS requests
@@ -90,24 +150,35 @@ func PreflightPaths(svc string) []string {
 			}
 		}
 	}
-	for _, fs := range s.FileServers {
-		fps := fs.RequestPaths
-		for _, fp := range fps {
-			found := false
-			for _, p := range paths {
-				if fp == p {
-					found = true
-					break
-				}
-			}
-			if !found {
-				paths = append(paths, fp)
+	for _, fp := range FileServerPaths(svc) {
+		found := false
+		for _, p := range paths {
+			if fp == p {
+				found = true
+				break
 			}
 		}
+		if !found {
+			paths = append(paths, fp)
+		}
 	}
 	return paths
 }
 
+// sortOrigins returns the given origin expressions sorted by origin string.
+func sortOrigins(origins map[string]*OriginExpr) []*OriginExpr {
+	names := make([]string, 0, len(origins))
+	for n := range origins {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	oexps := make([]*OriginExpr, 0, len(names))
+	for _, n := range names {
+		oexps = append(oexps, origins[n])
+	}
+	return oexps
+}
+
 // EvalName returns the generic expression name used in error messages.
 func (o *OriginExpr) EvalName() string {
 	var suffix string
@@ -117,11 +188,42 @@ func (o *OriginExpr) EvalName() string {
 	return "CORS" + suffix
 }
 
-// Validate ensures the origin expression is valid.
+// SamePolicy returns true if o and other describe the same policy: same
+// origin, methods, headers, exposed headers, max age and flags.
+func (o *OriginExpr) SamePolicy(other *OriginExpr) bool {
+	return o.Origin == other.Origin && o.Regexp == other.Regexp && o.Func == other.Func &&
+		sameNames(o.Methods, other.Methods) && sameNames(o.Headers, other.Headers) &&
+		sameNames(o.Exposed, other.Exposed) && o.MaxAge == other.MaxAge &&
+		o.Credentials == other.Credentials && o.PrivateNetwork == other.PrivateNetwork &&
+		o.Strict == other.Strict && o.GRPCWeb == other.GRPCWeb
+}
+
+// FuncImport returns the import path of the package and the name of the
+// function identified by the origin of a Func origin expression.
+func (o *OriginExpr) FuncImport() (path, name string) {
+	i := strings.LastIndex(o.Origin, ".")
+	if i < 0 || strings.Contains(o.Origin[i:], "/") {
+		return "", o.Origin
+	}
+	return o.Origin[:i], o.Origin[i+1:]
+}
+
+// Validate ensures the origin expression is valid and that the policy it
+// describes is accepted by browsers.
 func (o *OriginExpr) Validate() *eval.ValidationErrors {
 	verr := new(eval.ValidationErrors)
-	if !o.Regexp && strings.Count(o.Origin, "*") > 1 {
-		verr.Add(o, "invalid origin, can only contain one wildcard character")
+	if o.Func {
+		if path, name := o.FuncImport(); path == "" || !token.IsIdentifier(name) || !token.IsExported(name) {
+			verr.Add(o, "invalid origin function %q, must be the import path of the package followed by the name of an exported function (e.g. github.com/acme/tenants.IsAllowedOrigin)", o.Origin)
+		}
+	} else if !o.Regexp && o.Origin != "*" {
+		if strings.Contains(o.Origin, "://") {
+			if msg := validateOriginPattern(o.Origin); msg != "" {
+				verr.Add(o, "invalid origin %q, %s", o.Origin, msg)
+			}
+		} else if strings.Count(o.Origin, "*") > 1 {
+			verr.Add(o, "invalid origin, can only contain one wildcard character unless it starts with a scheme (e.g. https://*.*.goa.design)")
+		}
 	}
 	if o.Regexp {
 		_, err := regexp.Compile(o.Origin)
@@ -129,5 +231,132 @@ func (o *OriginExpr) Validate() *eval.ValidationErrors {
 			verr.Add(o, "invalid origin, should be a valid regular expression")
 		}
 	}
+	if o.Credentials {
+		if o.Origin == "*" && !o.Regexp {
+			verr.Add(o, "origin %q cannot be used with Credentials, browsers reject credentialed responses for wildcard origins, list the authorized origins instead", o.Origin)
+		}
+		for _, h := range o.Headers {
+			if h == "*" {
+				verr.Add(o, "header \"*\" cannot be used with Credentials, browsers do not treat it as a wildcard for credentialed requests, list the authorized headers instead")
+			}
+		}
+		for _, m := range o.Methods {
+			if m == "*" {
+				verr.Add(o, "method \"*\" cannot be used with Credentials, browsers do not treat it as a wildcard for credentialed requests, list the authorized methods instead")
+			}
+		}
+	}
+	for _, m := range o.Methods {
+		if m != "*" && !isToken(m) {
+			verr.Add(o, "invalid method %q, must be a valid HTTP method name", m)
+		}
+	}
+	for _, h := range o.Headers {
+		if h != "*" && !isToken(h) {
+			verr.Add(o, "invalid header %q, must be a valid HTTP header name", h)
+		}
+	}
+	for _, h := range o.Exposed {
+		if h != "*" && !isToken(h) {
+			verr.Add(o, "invalid exposed header %q, must be a valid HTTP header name", h)
+		}
+	}
+	if s, ok := o.Parent.(*expr.ServiceExpr); ok {
+		for _, a := range Root.APIOrigins {
+			if a.Origin == o.Origin && a.Regexp == o.Regexp && a.Func == o.Func && !a.SamePolicy(o) {
+				verr.Add(o, "origin %q of service %q is already defined with a different policy in API %q, define the policy once or use the same settings in both", o.Origin, s.Name, apiName())
+				break
+			}
+		}
+	}
 	return verr
 }
+
+// apiName returns the name of the API defined in the design.
+func apiName() string {
+	if expr.Root == nil || expr.Root.API == nil {
+		return ""
+	}
+	return expr.Root.API.Name
+}
+
+// sameNames returns true if a and b contain the same names in the same order.
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, n := range a {
+		if b[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// validateOriginPattern validates an origin of the form scheme://host[:port]
+// where the host labels may contain wildcards and the port may be a wildcard.
+// It returns a message describing the error if the origin is invalid, an empty
+// string otherwise.
+func validateOriginPattern(origin string) string {
+	i := strings.Index(origin, "://")
+	scheme, host := origin[:i], origin[i+3:]
+	if scheme == "" || strings.Contains(scheme, "*") {
+		return "scheme must be set and cannot contain a wildcard"
+	}
+	var port string
+	if strings.HasPrefix(host, "[") {
+		j := strings.Index(host, "]")
+		if j < 0 {
+			return "missing closing bracket in IPv6 host"
+		}
+		if rest := host[j+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ":") {
+				return "host must be followed by a port"
+			}
+			port = rest[1:]
+			if port == "" {
+				return "port cannot be empty"
+			}
+		}
+		host = host[:j+1]
+	} else if j := strings.LastIndex(host, ":"); j >= 0 {
+		port = host[j+1:]
+		host = host[:j]
+		if port == "" {
+			return "port cannot be empty"
+		}
+	}
+	if strings.ContainsAny(host, "/?#") {
+		return "origin cannot contain a path, query or fragment"
+	}
+	for _, l := range strings.Split(host, ".") {
+		if l == "" {
+			return "host labels cannot be empty"
+		}
+	}
+	if port != "" && port != "*" {
+		for _, c := range port {
+			if c < '0' || c > '9' {
+				return "port must be a number or a wildcard"
+			}
+		}
+	}
+	return ""
+}
+
+// isToken returns true if s is a valid HTTP token as defined in RFC 7230
+// section 3.2.6, HTTP method and header names must be tokens.
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			continue
+		}
+		if !strings.ContainsRune("!#$%&'*+-.^_`|~", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+
+	"goa.design/goa/v3/expr"
+)
+
+func TestOriginExprValidate(t *testing.T) {
+	svc := &expr.ServiceExpr{Name: "Service"}
+	cases := []struct {
+		name   string
+		origin *OriginExpr
+		api    map[string]*OriginExpr
+		errors []string
+	}{
+		{"valid", &OriginExpr{Origin: "http://goa.design", Methods: []string{"GET", "POST"}, Headers: []string{"X-Shared-Secret"}, Exposed: []string{"X-Time"}, Credentials: true}, nil, nil},
+		{"wildcard-headers", &OriginExpr{Origin: "*", Headers: []string{"*"}, Methods: []string{"*"}}, nil, nil},
+		{"too-many-wildcards", &OriginExpr{Origin: "*.*.goa.design"}, nil, []string{"can only contain one wildcard character"}},
+		{"scheme-wildcards", &OriginExpr{Origin: "https://*.*.goa.design:*"}, nil, nil},
+		{"ipv6", &OriginExpr{Origin: "http://[::1]:*"}, nil, nil},
+		{"wildcard-scheme", &OriginExpr{Origin: "*://goa.design"}, nil, []string{"scheme must be set and cannot contain a wildcard"}},
+		{"empty-label", &OriginExpr{Origin: "https://*..goa.design"}, nil, []string{"host labels cannot be empty"}},
+		{"invalid-port", &OriginExpr{Origin: "http://localhost:8*"}, nil, []string{"port must be a number or a wildcard"}},
+		{"path", &OriginExpr{Origin: "https://goa.design/path"}, nil, []string{"origin cannot contain a path"}},
+		{"invalid-regexp", &OriginExpr{Origin: "(", Regexp: true}, nil, []string{"should be a valid regular expression"}},
+		{"wildcard-credentials", &OriginExpr{Origin: "*", Credentials: true}, nil, []string{`origin "*" cannot be used with Credentials`}},
+		{"wildcard-headers-credentials", &OriginExpr{Origin: "http://goa.design", Headers: []string{"*"}, Credentials: true}, nil, []string{`header "*" cannot be used with Credentials`}},
+		{"wildcard-methods-credentials", &OriginExpr{Origin: "http://goa.design", Methods: []string{"*"}, Credentials: true}, nil, []string{`method "*" cannot be used with Credentials`}},
+		{"invalid-method", &OriginExpr{Origin: "http://goa.design", Methods: []string{"GET POST"}}, nil, []string{`invalid method "GET POST"`}},
+		{"invalid-header", &OriginExpr{Origin: "http://goa.design", Headers: []string{"X-Shared:Secret"}}, nil, []string{`invalid header "X-Shared:Secret"`}},
+		{"invalid-exposed", &OriginExpr{Origin: "http://goa.design", Exposed: []string{""}}, nil, []string{`invalid exposed header ""`}},
+		{"conflict", &OriginExpr{Origin: "http://goa.design", Methods: []string{"GET"}, Parent: svc}, map[string]*OriginExpr{"http://goa.design": {Origin: "http://goa.design"}}, []string{`origin "http://goa.design" of service "Service" is already defined with a different policy in API "API"`}},
+		{"redefined", &OriginExpr{Origin: "http://goa.design", Methods: []string{"GET"}, Parent: svc}, map[string]*OriginExpr{"http://goa.design": {Origin: "http://goa.design", Methods: []string{"GET"}}}, nil},
+		{"func", &OriginExpr{Origin: "github.com/acme/tenants.IsAllowedOrigin", Func: true, Methods: []string{"GET"}}, nil, nil},
+		{"func-no-package", &OriginExpr{Origin: "IsAllowedOrigin", Func: true}, nil, []string{`invalid origin function "IsAllowedOrigin"`}},
+		{"func-unexported", &OriginExpr{Origin: "github.com/acme/tenants.isAllowedOrigin", Func: true}, nil, []string{`invalid origin function "github.com/acme/tenants.isAllowedOrigin"`}},
+		{"duplicate-regexp", &OriginExpr{Origin: "goa", Regexp: true, Parent: svc}, map[string]*OriginExpr{"goa": {Origin: "goa"}}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			apiOrigins, root := Root.APIOrigins, expr.Root
+			defer func() { Root.APIOrigins, expr.Root = apiOrigins, root }()
+			Root.APIOrigins = c.api
+			expr.Root = &expr.RootExpr{API: &expr.APIExpr{Name: "API"}}
+
+			verr := c.origin.Validate()
+			if len(verr.Errors) != len(c.errors) {
+				t.Fatalf("got %d errors, expected %d: %s", len(verr.Errors), len(c.errors), verr.Error())
+			}
+			for i, e := range c.errors {
+				if !strings.Contains(verr.Errors[i].Error(), e) {
+					t.Errorf("got error %q, expected to contain %q", verr.Errors[i].Error(), e)
+				}
+			}
+		})
+	}
+}
+
+func TestFileServerPaths(t *testing.T) {
+	root := expr.Root
+	defer func() { expr.Root = root }()
+	svc := &expr.HTTPServiceExpr{
+		ServiceExpr: &expr.ServiceExpr{Name: "Service"},
+		FileServers: []*expr.HTTPFileServerExpr{
+			{RequestPaths: []string{"/favicon.ico"}},
+			{RequestPaths: []string{"/static/{*filepath}", "/api/static/{*filepath}"}},
+			{RequestPaths: []string{"/docs/{*path}", "/docs"}},
+		},
+	}
+	expr.Root = &expr.RootExpr{API: &expr.APIExpr{HTTP: &expr.HTTPExpr{Services: []*expr.HTTPServiceExpr{svc}}}}
+	expected := []string{"/favicon.ico", "/static/", "/static/{*filepath}", "/api/static/", "/api/static/{*filepath}", "/docs/", "/docs/{*path}", "/docs"}
+
+	paths := FileServerPaths("Service")
+
+	if len(paths) != len(expected) {
+		t.Fatalf("got %d paths %v, expected %d %v", len(paths), paths, len(expected), expected)
+	}
+	for i, p := range expected {
+		if paths[i] != p {
+			t.Errorf("got path %q at index %d, expected %q", paths[i], i, p)
+		}
+	}
+	if paths := FileServerPaths("Unknown"); len(paths) != 0 {
+		t.Errorf("got paths %v for unknown service, expected none", paths)
+	}
+}
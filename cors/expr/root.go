@@ -9,6 +9,8 @@ import (
 var Root = &RootExpr{
 	APIOrigins:     map[string]*OriginExpr{},
 	ServiceOrigins: map[string]map[string]*OriginExpr{},
+	MethodOrigins:  map[string]map[string]map[string]*OriginExpr{},
+	Tests:          map[string]bool{},
 }
 
 type (
@@ -20,6 +22,12 @@ type (
 		// ServiceOrigins lists all the CORS definitions indexed by origin string
 		// at the service level.
 		ServiceOrigins map[string]map[string]*OriginExpr
+		// MethodOrigins lists all the CORS definitions indexed by service
+		// name, method name and origin string at the method level.
+		MethodOrigins map[string]map[string]map[string]*OriginExpr
+		// Tests lists the names of the services for which the CORS tests
+		// are generated, the empty name enables the tests for all services.
+		Tests map[string]bool
 	}
 )
 
@@ -33,7 +41,8 @@ func (r *RootExpr) EvalName() string {
 	return "CORS plugin"
 }
 
-// WalkSets iterates over the API-level and service-level CORS definitions.
+// WalkSets iterates over the API-level, service-level and method-level CORS
+// definitions.
 func (r *RootExpr) WalkSets(walk eval.SetWalker) {
 	oexps := make(eval.ExpressionSet, 0, len(r.APIOrigins))
 	for _, o := range r.APIOrigins {
@@ -47,6 +56,15 @@ func (r *RootExpr) WalkSets(walk eval.SetWalker) {
 		}
 	}
 	walk(oexps)
+	oexps = make(eval.ExpressionSet, 0, len(r.MethodOrigins))
+	for _, s := range r.MethodOrigins {
+		for _, m := range s {
+			for _, o := range m {
+				oexps = append(oexps, o)
+			}
+		}
+	}
+	walk(oexps)
 }
 
 // DependsOn tells the eval engine to run the goa DSL first.
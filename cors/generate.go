@@ -1,6 +1,8 @@
 package cors
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -8,6 +10,8 @@ import (
 	"goa.design/goa/v3/codegen"
 	"goa.design/goa/v3/codegen/service"
 	"goa.design/goa/v3/eval"
+	goaexpr "goa.design/goa/v3/expr"
+	grpccodegen "goa.design/goa/v3/grpc/codegen"
 	httpcodegen "goa.design/goa/v3/http/codegen"
 	"goa.design/plugins/v3/cors/expr"
 )
@@ -24,26 +28,86 @@ type (
 		Origins []*expr.OriginExpr
 		// OriginHandler is the name of the handler function that sets CORS headers.
 		OriginHandler string
+		// FilesOriginHandler is the name of the handler function that sets
+		// the CORS headers of the file servers, empty if the service has no
+		// file server.
+		FilesOriginHandler string
 		// PreflightPaths is the list of paths that should handle OPTIONS requests.
 		PreflightPaths []string
+		// Preflights lists the preflight paths together with the origin
+		// handlers that serve them.
+		Preflights []*PreflightData
+		// Methods lists the origin handlers of the service methods.
+		Methods []*MethodData
 		// Endpoint is the CORS endpoint data.
 		Endpoint *httpcodegen.EndpointData
 	}
+
+	// MethodData contains the data necessary to generate the origin handler
+	// of a method.
+	MethodData struct {
+		// Name is the name of the method.
+		Name string
+		// ServiceName is the name of the service.
+		ServiceName string
+		// ServiceOriginHandler is the name of the origin handler of the
+		// service.
+		ServiceOriginHandler string
+		// Origins is the list of origin expressions defined in the method,
+		// nil if the method uses the CORS policy of the service.
+		Origins []*expr.OriginExpr
+		// OriginHandler is the name of the handler function that sets CORS
+		// headers.
+		OriginHandler string
+		// VarName is the name of the variable holding the origin handler in
+		// the CORS mount function.
+		VarName string
+	}
+
+	// PreflightData describes a path that handles OPTIONS requests.
+	PreflightData struct {
+		// Path is the request path.
+		Path string
+		// Handler is the name of the variable holding the origin handler
+		// that serves the preflight requests.
+		Handler string
+		// Verbs lists the origin handlers indexed by the HTTP method of the
+		// actual request when the path is served by methods with different
+		// CORS policies. Verbs is empty when Handler serves all requests.
+		Verbs []*VerbData
+	}
+
+	// VerbData associates a HTTP method with the origin handler that serves
+	// the preflight requests for that method.
+	VerbData struct {
+		// Verb is the HTTP method.
+		Verb string
+		// Handler is the name of the variable holding the origin handler.
+		Handler string
+	}
 )
 
 // Register the plugin Generator functions.
 func init() {
-	codegen.RegisterPlugin("cors", "gen", nil, Generate)
+	codegen.RegisterPlugin("cors", "gen", Prepare, Generate)
 	codegen.RegisterPlugin("cors-example", "example", nil, TweakExample)
 }
 
 // Generate produces server code that handle preflight requests and updates
-// the HTTP responses with the appropriate CORS headers.
+// the HTTP responses with the appropriate CORS headers. It also produces the
+// tests of the origin handlers for the services that enable them.
 func Generate(genpkg string, roots []eval.Root, files []*codegen.File) ([]*codegen.File, error) {
+	var tests []*codegen.File
 	for _, f := range files {
-		serverCORS(f)
+		if err := serverCORS(f); err != nil {
+			return nil, err
+		}
+		openapiCORS(f)
+		if t := serverCORSTest(f); t != nil {
+			tests = append(tests, t)
+		}
 	}
-	return files, nil
+	return append(files, tests...), nil
 }
 
 // TweakExample handles the special case where a service only has file servers
@@ -72,11 +136,40 @@ func buildServiceData(svc string) *ServiceData {
 		routes[i] = &httpcodegen.RouteData{Verb: "OPTIONS", Path: p}
 	}
 
+	var (
+		methods  []*MethodData
+		handlers = make(map[string]string)
+		handler  = "Handle" + codegen.Goify(svc, true) + "Origin"
+	)
+	if s := goaexpr.Root.API.HTTP.Service(svc); s != nil {
+		for _, e := range s.HTTPEndpoints {
+			name := codegen.Goify(e.MethodExpr.Name, true)
+			m := &MethodData{
+				Name:                 e.MethodExpr.Name,
+				ServiceName:          svc,
+				ServiceOriginHandler: handler,
+				Origins:              expr.MethodOrigins(svc, e.MethodExpr.Name),
+				OriginHandler:        "Handle" + codegen.Goify(svc, true) + name + "Origin",
+				VarName:              "h" + name,
+			}
+			methods = append(methods, m)
+			handlers[e.MethodExpr.Name] = m.VarName
+		}
+	}
+
+	var filesHandler string
+	if len(expr.FileServerPaths(svc)) > 0 {
+		filesHandler = "Handle" + codegen.Goify(svc, true) + "FilesOrigin"
+	}
+
 	return &ServiceData{
-		Name:           svc,
-		Origins:        expr.Origins(svc),
-		PreflightPaths: preflights,
-		OriginHandler:  "Handle" + codegen.Goify(svc, true) + "Origin",
+		Name:               svc,
+		Origins:            expr.Origins(svc),
+		PreflightPaths:     preflights,
+		Preflights:         buildPreflightData(svc, preflights, handlers),
+		Methods:            methods,
+		OriginHandler:      handler,
+		FilesOriginHandler: filesHandler,
 		Endpoint: &httpcodegen.EndpointData{
 			Method: &service.MethodData{
 				VarName: "CORS",
@@ -88,18 +181,96 @@ func buildServiceData(svc string) *ServiceData {
 	}
 }
 
+// buildPreflightData returns the origin handlers serving each preflight path.
+// handlers maps the names of the methods that define their own CORS policy to
+// the variable holding their origin handler. The service origin handler is held
+// in the variable "h" and the origin handler of the file servers in the variable
+// "hFiles".
+func buildPreflightData(svc string, paths []string, handlers map[string]string) []*PreflightData {
+	verbs := make(map[string][]*VerbData)
+	if s := goaexpr.Root.API.HTTP.Service(svc); s != nil {
+		for _, e := range s.HTTPEndpoints {
+			h, ok := handlers[e.MethodExpr.Name]
+			if !ok {
+				h = "h"
+			}
+			for _, r := range e.Routes {
+				if r.Method == "OPTIONS" {
+					continue
+				}
+				for _, fp := range r.FullPaths() {
+					verbs[fp] = append(verbs[fp], &VerbData{Verb: r.Method, Handler: h})
+				}
+			}
+		}
+	}
+	files := make(map[string]bool)
+	for _, p := range expr.FileServerPaths(svc) {
+		files[p] = true
+	}
+	data := make([]*PreflightData, len(paths))
+	for i, p := range paths {
+		pd := &PreflightData{Path: p, Handler: "h"}
+		vs := verbs[p]
+		if len(vs) == 0 && files[p] {
+			pd.Handler = "hFiles"
+		}
+		if len(vs) > 0 {
+			pd.Handler = vs[0].Handler
+			for _, v := range vs[1:] {
+				if v.Handler != pd.Handler {
+					pd.Handler = "h"
+					for _, v := range vs {
+						if v.Handler != "h" {
+							pd.Verbs = append(pd.Verbs, v)
+						}
+					}
+					break
+				}
+			}
+		}
+		data[i] = pd
+	}
+	return data
+}
+
+// PreflightUses returns true if the preflight paths are served by the origin
+// handler held in the given variable of the generated mount function.
+func (d *ServiceData) PreflightUses(handler string) bool {
+	for _, p := range d.Preflights {
+		if p.Handler == handler {
+			return true
+		}
+		if len(p.Verbs) > 0 && handler == "h" {
+			// Default case of the switch on the request method
+			return true
+		}
+		for _, v := range p.Verbs {
+			if v.Handler == handler {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // serverCORS updates the HTTP server file to handle preflight paths and
-// adds the required CORS headers to the response.
-func serverCORS(f *codegen.File) {
+// adds the required CORS headers to the response. The origin handlers are
+// configured with the options given to the server constructor.
+func serverCORS(f *codegen.File) error {
 	if filepath.Base(f.Path) != "server.go" {
-		return
+		return nil
 	}
 
 	var svcData *ServiceData
 	for _, s := range f.Section("server-struct") {
 
+		if data, ok := s.Data.(*grpccodegen.ServiceData); ok {
+			grpcServerCORS(f, data.Service.Name)
+			return nil
+		}
 		data, ok := s.Data.(*httpcodegen.ServiceData)
-		if !ok { // other transport, e.g. gRPC
+		if !ok { // other transport
 			continue
 		}
 
@@ -112,15 +283,15 @@ func serverCORS(f *codegen.File) {
 		} else {
 			svcData = d
 		}
-		for _, o := range svcData.Origins {
-			if o.Regexp {
-				codegen.AddImport(f.SectionTemplates[0],
-					&codegen.ImportSpec{Path: "regexp"})
-				break
-			}
-		}
+		imports, aliases := originFuncImports(f.SectionTemplates[0], svcData, true)
+		codegen.AddImport(f.SectionTemplates[0], imports...)
 		data.Endpoints = append(data.Endpoints, svcData.Endpoint)
-		fm := codegen.TemplateFuncs()
+		if err := replaceSource(s,
+			"{{ .VarName }} http.Handler\n\t{{- end }}\n}",
+			"{{ .VarName }} http.Handler\n\t{{- end }}\n\n\t// corsOptions configures the CORS origin handlers, see New.\n\tcorsOptions []cors.Option\n}"); err != nil {
+			return err
+		}
+		fm := templateFuncs(aliases)
 		f.SectionTemplates = append(f.SectionTemplates, &codegen.SectionTemplate{
 			Name:    "mount-cors",
 			Source:  mountCORST,
@@ -133,26 +304,217 @@ func serverCORS(f *codegen.File) {
 			Data:    svcData,
 			FuncMap: fm,
 		})
-		fm["join"] = strings.Join
 		f.SectionTemplates = append(f.SectionTemplates, &codegen.SectionTemplate{
 			Name:    "handle-cors",
 			Source:  handleCORST,
 			Data:    svcData,
 			FuncMap: fm,
 		})
+		if svcData.FilesOriginHandler != "" {
+			f.SectionTemplates = append(f.SectionTemplates, &codegen.SectionTemplate{
+				Name:    "handle-files-cors",
+				Source:  handleFilesCORST,
+				Data:    svcData,
+				FuncMap: fm,
+			})
+		}
+		for _, m := range svcData.Methods {
+			source := handleMethodCORST
+			if m.Origins == nil {
+				source = handleInheritedCORST
+			}
+			f.SectionTemplates = append(f.SectionTemplates, &codegen.SectionTemplate{
+				Name:    "handle-method-cors",
+				Source:  source,
+				Data:    m,
+				FuncMap: fm,
+			})
+		}
+	}
+	if svcData == nil {
+		return nil
 	}
 	for _, s := range f.Section("server-init") {
-		s.Source = strings.Replace(s.Source,
+		if err := replaceSource(s,
 			`e.{{ .Method.VarName }}, mux, {{ if .MultipartRequestDecoder }}{{ .MultipartRequestDecoder.InitName }}(mux, {{ .MultipartRequestDecoder.VarName }}){{ else }}decoder{{ end }}, encoder, errhandler, formatter{{ if isWebSocketEndpoint . }}, upgrader, configurer.{{ .Method.VarName }}Fn{{ end }})`,
-			`{{ if ne .Method.VarName "CORS" }}e.{{ .Method.VarName }}, mux, {{ if .MultipartRequestDecoder }}{{ .MultipartRequestDecoder.InitName }}(mux, {{ .MultipartRequestDecoder.VarName }}){{ else }}decoder{{ end }}, encoder, errhandler, formatter{{ if isWebSocketEndpoint . }}, upgrader, configurer.{{ .Method.VarName }}Fn{{ end }}{{ end }})`,
-			-1)
+			`{{ if ne .Method.VarName "CORS" }}e.{{ .Method.VarName }}, mux, {{ if .MultipartRequestDecoder }}{{ .MultipartRequestDecoder.InitName }}(mux, {{ .MultipartRequestDecoder.VarName }}){{ else }}decoder{{ end }}, encoder, errhandler, formatter{{ if isWebSocketEndpoint . }}, upgrader, configurer.{{ .Method.VarName }}Fn{{ end }}{{ end }})`); err != nil {
+			return err
+		}
+		if err := replaceSource(s,
+			"\n) *{{ .ServerStruct }} {",
+			"\n\tcorsOpts ...cors.Option,\n) *{{ .ServerStruct }} {"); err != nil {
+			return err
+		}
+		if err := replaceSource(s,
+			"\t\t{{- end }}\n\t}\n}",
+			"\t\t{{- end }}\n\t\tcorsOptions: corsOpts,\n\t}\n}"); err != nil {
+			return err
+		}
+		if svcData.FilesOriginHandler != "" {
+			if err := wrapFileServer(s, func(h string) string {
+				return svcData.FilesOriginHandler + "(" + h + ", corsOpts...)"
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, s := range f.Section("server-mount") {
+		if err := replaceSource(s,
+			"{{ .MountHandler }}(mux, h.{{ .Method.VarName }})",
+			"{{ .MountHandler }}(mux, h.{{ .Method.VarName }}, h.corsOptions...)"); err != nil {
+			return err
+		}
 	}
 	for _, s := range f.Section("server-handler") {
-		s.Source = strings.Replace(s.Source, "h.(http.HandlerFunc)", svcData.OriginHandler+"(h).(http.HandlerFunc)", -1)
+		ed, ok := s.Data.(*httpcodegen.EndpointData)
+		if !ok {
+			return fmt.Errorf("cors: unexpected data %T in goa section %q", s.Data, s.Name)
+		}
+		handler := svcData.OriginHandler
+		for _, m := range svcData.Methods {
+			if m.Name == ed.Method.Name {
+				handler = m.OriginHandler
+				break
+			}
+		}
+		if err := replaceSource(s,
+			"(mux goahttp.Muxer, h http.Handler) {",
+			"(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {"); err != nil {
+			return err
+		}
+		if err := replaceSource(s, "h.(http.HandlerFunc)", handler+"(h, opts...).(http.HandlerFunc)"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceSource replaces the first occurrence of old with new in the source of
+// the given goa section template. It returns an error if the source does not
+// contain old so that changes made to the goa templates are detected.
+func replaceSource(s *codegen.SectionTemplate, old, new string) error {
+	if !strings.Contains(s.Source, old) {
+		return fmt.Errorf("cors: cannot find %q in goa section %q, the goa version is not supported", old, s.Name)
+	}
+	s.Source = strings.Replace(s.Source, old, new, 1)
+	return nil
+}
+
+// wrapFileServer replaces the expression initializing the file server handlers
+// in the given goa server init section with the result of wrap. The whole
+// expression is wrapped so that the generated code does not depend on the
+// order in which the plugins wrapping the file server handlers are run. It
+// returns an error if the section does not initialize file server handlers.
+func wrapFileServer(s *codegen.SectionTemplate, wrap func(string) string) error {
+	const init = "{{ .VarName }}: "
+	start := strings.Index(s.Source, init)
+	if start < 0 {
+		return fmt.Errorf("cors: cannot find %q in goa section %q, the goa version is not supported", init, s.Name)
+	}
+	start += len(init)
+	end := strings.Index(s.Source[start:], ",\n")
+	if end < 0 {
+		return fmt.Errorf("cors: cannot find the end of the file server handler in goa section %q, the goa version is not supported", s.Name)
+	}
+	end += start
+	s.Source = s.Source[:start] + wrap(s.Source[start:end]) + s.Source[end:]
+	return nil
+}
+
+// grpcServerCORS adds the origin handler of the service to the gRPC server
+// file when one of the service origins authorizes gRPC-Web clients.
+func grpcServerCORS(f *codegen.File, svc string) {
+	var grpcWeb bool
+	for _, o := range expr.Origins(svc) {
+		if o.GRPCWeb {
+			grpcWeb = true
+			break
+		}
+	}
+	if !grpcWeb {
+		return
+	}
+	svcData, ok := ServicesData[svc]
+	if !ok {
+		svcData = buildServiceData(svc)
+		ServicesData[svc] = svcData
+	}
+	codegen.AddImport(f.SectionTemplates[0],
+		&codegen.ImportSpec{Path: "net/http"},
+		&codegen.ImportSpec{Path: "goa.design/plugins/v3/cors"})
+	imports, aliases := originFuncImports(f.SectionTemplates[0], svcData, false)
+	codegen.AddImport(f.SectionTemplates[0], imports...)
+	fm := templateFuncs(aliases)
+	f.SectionTemplates = append(f.SectionTemplates, &codegen.SectionTemplate{
+		Name:    "handle-cors",
+		Source:  grpcHandleCORST,
+		Data:    svcData,
+		FuncMap: fm,
+	})
+}
+
+// templateFuncs returns the functions used by the CORS templates. aliases
+// maps the import paths of the packages defining the origin functions to the
+// names used to refer to them, see originFuncImports.
+func templateFuncs(aliases map[string]string) map[string]interface{} {
+	fm := codegen.TemplateFuncs()
+	fm["originFunc"] = func(o *expr.OriginExpr) string {
+		pkg, name := o.FuncImport()
+		return aliases[pkg] + "." + name
+	}
+	return fm
+}
+
+// originFuncImports returns the imports of the packages defining the origin
+// functions of the service origin expressions and if methods is true of the
+// method origin expressions. It also returns the names used to refer to the
+// packages indexed by import path. The names do not collide with the packages
+// already imported by the given header section nor with the parameters of the
+// origin handlers.
+func originFuncImports(header *codegen.SectionTemplate, data *ServiceData, methods bool) ([]*codegen.ImportSpec, map[string]string) {
+	origins := data.Origins
+	if methods {
+		for _, m := range data.Methods {
+			origins = append(origins[:len(origins):len(origins)], m.Origins...)
+		}
+	}
+	reserved := []string{"http", "cors", "goahttp", "h", "opts", "w", "r"}
+	if hdata, ok := header.Data.(map[string]interface{}); ok {
+		if specs, ok := hdata["Imports"].([]*codegen.ImportSpec); ok {
+			for _, spec := range specs {
+				if spec.Name != "" {
+					reserved = append(reserved, spec.Name)
+				} else {
+					reserved = append(reserved, path.Base(spec.Path))
+				}
+			}
+		}
+	}
+	scope := codegen.NewNameScope()
+	for _, name := range reserved {
+		if scope.Name(name) == name {
+			scope.Unique(name)
+		}
 	}
-	for _, s := range f.Section("server-files") {
-		s.Source = strings.Replace(s.Source, "h.ServeHTTP", svcData.OriginHandler+"(h).ServeHTTP", -1)
+	var imports []*codegen.ImportSpec
+	aliases := make(map[string]string)
+	for _, o := range origins {
+		if !o.Func {
+			continue
+		}
+		pkg, _ := o.FuncImport()
+		if _, ok := aliases[pkg]; ok {
+			continue
+		}
+		name := scope.Unique(codegen.Goify(path.Base(pkg), false))
+		aliases[pkg] = name
+		spec := &codegen.ImportSpec{Path: pkg}
+		if name != path.Base(pkg) {
+			spec.Name = name
+		}
+		imports = append(imports, spec)
 	}
+	return imports, aliases
 }
 
 // Data: ServiceData
@@ -165,62 +527,112 @@ func {{ .Endpoint.HandlerInit }}() http.Handler {
 `
 
 // Data: ServiceData
-var mountCORST = `{{ printf "%s configures the mux to serve the CORS endpoints for the service %s." .Endpoint.MountHandler .Name | comment }}
-func {{ .Endpoint.MountHandler }}(mux goahttp.Muxer, h http.Handler) {
-	h = {{ .OriginHandler }}(h)
-	{{- range $p := .PreflightPaths }}
-	mux.Handle("OPTIONS", "{{ $p }}", h.ServeHTTP)
+var mountCORST = `{{ printf "%s configures the mux to serve the CORS endpoints for the service %s. The origin handlers are configured with the given options." .Endpoint.MountHandler .Name | comment }}
+func {{ .Endpoint.MountHandler }}(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	{{- range .Methods }}
+		{{- if $.PreflightUses .VarName }}
+	{{ .VarName }} := {{ .OriginHandler }}(h, opts...)
+		{{- end }}
+	{{- end }}
+	{{- if and .FilesOriginHandler (.PreflightUses "hFiles") }}
+	hFiles := {{ .FilesOriginHandler }}(h, opts...)
+	{{- end }}
+	{{- if .PreflightUses "h" }}
+	h = {{ .OriginHandler }}(h, opts...)
+	{{- end }}
+	{{- range $p := .Preflights }}
+		{{- if $p.Verbs }}
+	mux.Handle("OPTIONS", "{{ $p.Path }}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Access-Control-Request-Method") {
+			{{- range $p.Verbs }}
+		case "{{ .Verb }}":
+			{{ .Handler }}.ServeHTTP(w, r)
+			{{- end }}
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+		{{- else }}
+	mux.Handle("OPTIONS", "{{ $p.Path }}", {{ $p.Handler }}.ServeHTTP)
+		{{- end }}
 	{{- end }}
 }
 `
 
 // Data: ServiceData
-var handleCORST = `{{ printf "%s applies the CORS response headers corresponding to the origin for the service %s." .OriginHandler .Name | comment }}
-func {{ .OriginHandler }}(h http.Handler) http.Handler {
-{{- range $i, $policy := .Origins }}
-	{{- if $policy.Regexp }}
-	spec{{$i}} := regexp.MustCompile({{ printf "%q" $policy.Origin }})
-	{{- end }}
-{{- end }}
+var handleCORST = `{{ define "policy-args" }}{{ printf "%q, %q" .Name "" }}{{ end -}}
+{{ define "handler" }}h{{ end -}}
+{{ printf "%s applies the CORS response headers corresponding to the origin for the service %s." .OriginHandler .Name | comment }}
+` + originHandlerT
+
+// Data: ServiceData
+var grpcHandleCORST = `{{ define "policy-args" }}{{ printf "%q, %q" .Name "" }}{{ end -}}
+{{ define "handler" }}cors.EndPreflight(h){{ end -}}
+{{ printf "%s applies the CORS response headers corresponding to the origin for the service %s. It responds to the authorized preflight requests without calling h." .OriginHandler .Name | comment }}
+` + originHandlerT
+
+// Data: ServiceData
+var handleFilesCORST = `{{ printf "%s applies the CORS response headers corresponding to the origin for the file servers of the service %s. It authorizes the conditional and range requests and exposes the headers of the partial and cached content responses." .FilesOriginHandler .Name | comment }}
+func {{ .FilesOriginHandler }}(h http.Handler, opts ...cors.Option) http.Handler {
+	h = {{ .OriginHandler }}(h, opts...)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-	origin := r.Header.Get("Origin")
-	if origin == "" {
-		// Not a CORS request
-		h.ServeHTTP(w, r)
-		return
-	}
-	{{- range $i, $policy := .Origins }}
-		{{- if $policy.Regexp }}
-	if cors.MatchOriginRegexp(origin, spec{{$i}}) {
-		{{- else }}
-	if cors.MatchOrigin(origin, {{ printf "%q" $policy.Origin }}) {
-		{{- end }}
-		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Vary", "Origin")
-			{{- if $policy.Exposed }}
-		w.Header().Set("Access-Control-Expose-Headers", "{{ join $policy.Exposed ", " }}")
+		h.ServeHTTP(w, r.WithContext(cors.WithFileServer(r.Context())))
+	})
+}
+`
+
+// Data: MethodData
+var handleMethodCORST = `{{ define "policy-args" }}{{ printf "%q, %q" .ServiceName .Name }}{{ end -}}
+{{ define "handler" }}h{{ end -}}
+{{ printf "%s applies the CORS response headers corresponding to the origin for the method %s of the service %s." .OriginHandler .Name .ServiceName | comment }}
+` + originHandlerT
+
+// Data: MethodData
+var handleInheritedCORST = `{{ printf "%s applies the CORS response headers corresponding to the origin for the method %s of the service %s. The method uses the CORS policies of the service unless the policy provider returns policies for the method." .OriginHandler .Name .ServiceName | comment }}
+func {{ .OriginHandler }}(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.MethodHandler({{ printf "%q, %q" .ServiceName .Name }}, {{ .ServiceOriginHandler }}, opts...)(h)
+}
+`
+
+// Data: ServiceData or MethodData, the including template must define the
+// "policy-args" template rendering the arguments given to the policy provider
+// and the "handler" template rendering the handler wrapped by the origin
+// handler.
+var originHandlerT = `func {{ .OriginHandler }}(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler({{ template "policy-args" . }}, []cors.Policy{
+	{{- range .Origins }}
+		{
+			{{- if .Func }}
+			OriginFunc: {{ originFunc . }},
+			{{- else }}
+			Origin: {{ if .Regexp }}{{ printf "/%s/" .Origin | printf "%q" }}{{ else }}{{ printf "%q" .Origin }}{{ end }},
 			{{- end }}
-			{{- if gt $policy.MaxAge 0 }}
-		w.Header().Set("Access-Control-Max-Age", "{{ $policy.MaxAge }}")
+			{{- if .Methods }}
+			Methods: {{ printf "%#v" .Methods }},
 			{{- end }}
-			{{- if eq $policy.Credentials true }}
-		w.Header().Set("Access-Control-Allow-Credentials", "{{ $policy.Credentials }}")
+			{{- if .Exposed }}
+			Exposed: {{ printf "%#v" .Exposed }},
 			{{- end }}
-		if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-			// We are handling a preflight request
-				{{- if $policy.Methods }}
-			w.Header().Set("Access-Control-Allow-Methods", "{{ join $policy.Methods ", " }}")
-				{{- end }}
-				{{- if $policy.Headers }}
-			w.Header().Set("Access-Control-Allow-Headers", "{{ join $policy.Headers ", " }}")
-				{{- end }}
-		}
-		h.ServeHTTP(w, r)
-		return
-	}
+			{{- if .Headers }}
+			Headers: {{ printf "%#v" .Headers }},
+			{{- end }}
+			{{- if gt .MaxAge 0 }}
+			MaxAge: {{ .MaxAge }},
+			{{- end }}
+			{{- if .Credentials }}
+			Credentials: true,
+			{{- end }}
+			{{- if .PrivateNetwork }}
+			PrivateNetwork: true,
+			{{- end }}
+			{{- if .Strict }}
+			Strict: true,
+			{{- end }}
+			{{- if .GRPCWeb }}
+			GRPCWeb: true,
+			{{- end }}
+		},
 	{{- end }}
-	h.ServeHTTP(w, r)
-	return
-  })
+	}, opts...)({{ template "handler" . }})
 }
 `
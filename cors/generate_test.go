@@ -8,6 +8,7 @@ import (
 	"goa.design/goa/v3/codegen"
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
+	grpccodegen "goa.design/goa/v3/grpc/codegen"
 	httpcodegen "goa.design/goa/v3/http/codegen"
 	"goa.design/plugins/v3/cors"
 	"goa.design/plugins/v3/cors/testdata"
@@ -36,6 +37,7 @@ func NewCORSHandler() http.Handler {
 		{"origin-multi-endpoint", testdata.OriginMultiEndpointDSL, []string{testdata.OriginMultiEndpointHandleCode}, []string{testdata.OriginMultiEndpointMountCode}, []string{testdata.OriginMultiEndpointServerInitCode}, 2},
 		{"multiservice-origin", testdata.MultiServiceSameOriginDSL, []string{testdata.MultiServiceSameOriginFirstServiceHandleCode, testdata.MultiServiceSameOriginSecondServiceHandleCode}, []string{testdata.MultiServiceSameOriginFirstServiceMountCode, testdata.MultiServiceSameOriginSecondServiceMountCode}, []string{testdata.MultiServiceSameOriginFirstServiceInitCode, testdata.MultiServiceSameOriginSecondServiceInitCode}, 4},
 		{"files", testdata.FilesDSL, []string{testdata.FilesHandleCode}, []string{testdata.FilesMountCode}, []string{testdata.FilesServerInitCode}, 1},
+		{"strict-origin", testdata.StrictOriginDSL, []string{testdata.StrictOriginHandleCode}, []string{testdata.StrictOriginMountCode}, []string{testdata.StrictOriginServerInitCode}, 2},
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
@@ -44,7 +46,9 @@ func NewCORSHandler() http.Handler {
 			if len(fs) != c.CodeGenCount {
 				t.Fatalf("got %d files, expected %d", len(fs), c.CodeGenCount)
 			}
-			cors.Generate("", []eval.Root{expr.Root}, fs)
+			if _, err := cors.Generate("", []eval.Root{expr.Root}, fs); err != nil {
+				t.Fatal(err)
+			}
 			expectedCodeIndex := -1
 			for _, f := range fs {
 				if filepath.Base(f.Path) != "server.go" {
@@ -55,26 +59,157 @@ func NewCORSHandler() http.Handler {
 				testCode(t, f, "mount-cors", c.MountCORSCode[expectedCodeIndex])
 				testCode(t, f, "cors-handler-init", corsHandler)
 				testCode(t, f, "server-init", c.ServerInitCode[expectedCodeIndex])
-				var originHndlr string
+				var filesOriginHndlr string
 				for _, s := range f.Section("handle-cors") {
-					data := s.Data.(*cors.ServiceData)
-					originHndlr = data.OriginHandler
+					filesOriginHndlr = s.Data.(*cors.ServiceData).FilesOriginHandler
+				}
+				handlers := make(map[string]string)
+				for _, s := range f.Section("handle-method-cors") {
+					data := s.Data.(*cors.MethodData)
+					handlers[data.Name] = data.OriginHandler
 				}
 				for _, s := range f.Section("server-handler") {
-					if !strings.Contains(s.Source, originHndlr) {
-						t.Errorf("server-handler: invalid code, expected to contain %s", originHndlr)
+					data := s.Data.(*httpcodegen.EndpointData)
+					if h := handlers[data.Method.Name] + "(h, opts...)"; !strings.Contains(s.Source, h) {
+						t.Errorf("server-handler %s: invalid code, expected to contain %s", data.Method.Name, h)
 					}
 				}
-				for _, s := range f.Section("server-files") {
-					if !strings.Contains(s.Source, originHndlr) {
-						t.Errorf("server-handler: invalid code, expected to contain %s", originHndlr)
+				if filesOriginHndlr != "" {
+					for _, s := range f.Section("server-init") {
+						if h := filesOriginHndlr + "(http.FileServer("; !strings.Contains(s.Source, h) {
+							t.Errorf("server-init: invalid code, expected to contain %s", h)
+						}
 					}
 				}
+				if filesOriginHndlr != "" && len(f.Section("handle-files-cors")) != 1 {
+					t.Errorf("handle-files-cors: got %d sections, expected 1", len(f.Section("handle-files-cors")))
+				}
 			}
 		})
 	}
 }
 
+func TestGenerateMethodOrigin(t *testing.T) {
+	httpcodegen.RunHTTPDSL(t, testdata.MethodOriginDSL)
+	fs := httpcodegen.ServerFiles("", expr.Root)
+	if _, err := cors.Generate("", []eval.Root{expr.Root}, fs); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"MethodOriginList":   "HandleMethodOriginMethodOriginListOrigin(h, opts...)",
+		"MethodOriginDelete": "HandleMethodOriginMethodOriginDeleteOrigin(h, opts...)",
+		"MethodOriginReset":  "HandleMethodOriginMethodOriginResetOrigin(h, opts...)",
+	}
+	for _, f := range fs {
+		if filepath.Base(f.Path) != "server.go" {
+			continue
+		}
+		testCode(t, f, "mount-cors", testdata.MethodOriginMountCode)
+		sections := f.Section("handle-method-cors")
+		if len(sections) != 3 {
+			t.Fatalf("handle-method-cors: got %d sections, expected 3", len(sections))
+		}
+		for i, exp := range []string{testdata.MethodOriginListHandleCode, testdata.MethodOriginDeleteHandleCode, testdata.MethodOriginResetHandleCode} {
+			code := codegen.SectionCode(t, sections[i])
+			if code != exp {
+				t.Errorf("invalid code, got:\n%s\ngot vs. expected:\n%s", code, codegen.Diff(t, code, exp))
+			}
+		}
+		for _, s := range f.Section("server-handler") {
+			data := s.Data.(*httpcodegen.EndpointData)
+			if !strings.Contains(s.Source, expected[data.Method.Name]) {
+				t.Errorf("server-handler %s: invalid code, expected to contain %s", data.Method.Name, expected[data.Method.Name])
+			}
+		}
+	}
+}
+
+func TestGenerateGRPCWeb(t *testing.T) {
+	grpccodegen.RunGRPCDSL(t, testdata.GRPCWebOriginDSL)
+	fs := grpccodegen.ServerFiles("", expr.Root)
+	if _, err := cors.Generate("", []eval.Root{expr.Root}, fs); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range fs {
+		if filepath.Base(f.Path) != "server.go" {
+			continue
+		}
+		found = true
+		testCode(t, f, "handle-cors", testdata.GRPCWebOriginHandleCode)
+	}
+	if !found {
+		t.Fatal("gRPC server file not found")
+	}
+}
+
+func TestGenerateTests(t *testing.T) {
+	httpcodegen.RunHTTPDSL(t, testdata.GenerateTestsDSL)
+	fs := httpcodegen.ServerFiles("", expr.Root)
+	fs, err := cors.Generate("", []eval.Root{expr.Root}, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, f := range fs {
+		if filepath.Base(f.Path) != "cors_test.go" {
+			continue
+		}
+		found = true
+		if dir := filepath.Dir(f.Path); dir != filepath.Join("gen", "http", "generate_tests", "server") {
+			t.Errorf("got directory %q", dir)
+		}
+		testCode(t, f, "cors-test", testdata.GenerateTestsTestCode)
+	}
+	if !found {
+		t.Fatal("cors_test.go not generated")
+	}
+}
+
+func TestGenerateOriginFunc(t *testing.T) {
+	httpcodegen.RunHTTPDSL(t, testdata.OriginFuncDSL)
+	fs := httpcodegen.ServerFiles("", expr.Root)
+	if _, err := cors.Generate("", []eval.Root{expr.Root}, fs); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fs {
+		if filepath.Base(f.Path) != "server.go" {
+			continue
+		}
+		testCode(t, f, "handle-cors", testdata.OriginFuncHandleCode)
+		imports := make(map[string]string)
+		for _, spec := range f.SectionTemplates[0].Data.(map[string]interface{})["Imports"].([]*codegen.ImportSpec) {
+			imports[spec.Path] = spec.Name
+		}
+		expected := map[string]string{
+			"github.com/acme/tenants":  "",
+			"gopkg.in/acme/allow.v1":   "allowV1",
+			"github.com/acme/goahttp":  "goahttp2",
+			"github.com/other/tenants": "tenants2",
+		}
+		for path, name := range expected {
+			if n, ok := imports[path]; !ok || n != name {
+				t.Errorf("import %q: got name %q (found: %v), expected %q", path, n, ok, name)
+			}
+		}
+	}
+}
+
+func TestGenerateDirFiles(t *testing.T) {
+	httpcodegen.RunHTTPDSL(t, testdata.DirFilesDSL)
+	fs := httpcodegen.ServerFiles("", expr.Root)
+	if _, err := cors.Generate("", []eval.Root{expr.Root}, fs); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fs {
+		if filepath.Base(f.Path) != "server.go" {
+			continue
+		}
+		testCode(t, f, "mount-cors", testdata.DirFilesMountCode)
+		testCode(t, f, "server-init", testdata.DirFilesServerInitCode)
+	}
+}
+
 func testCode(t *testing.T, file *codegen.File, section, expCode string) {
 	sections := file.Section(section)
 	if len(sections) < 1 {
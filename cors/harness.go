@@ -0,0 +1,310 @@
+package cors
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"goa.design/goa/v3/codegen"
+	httpcodegen "goa.design/goa/v3/http/codegen"
+	"goa.design/plugins/v3/cors/expr"
+)
+
+type (
+	// harnessData contains the data necessary to generate the test of an
+	// origin handler.
+	harnessData struct {
+		// OriginHandler is the name of the tested origin handler.
+		OriginHandler string
+		// Cases lists the test cases.
+		Cases []*harnessCaseData
+	}
+
+	// harnessCaseData describes a request made to an origin handler and the
+	// expected CORS response headers.
+	harnessCaseData struct {
+		// Name is the name of the test case.
+		Name string
+		// Method is the request HTTP method.
+		Method string
+		// Origin is the request Origin header.
+		Origin string
+		// RequestMethod is the Access-Control-Request-Method header of
+		// preflight requests.
+		RequestMethod string
+		// AllowOrigin is the expected Access-Control-Allow-Origin header.
+		AllowOrigin string
+		// Credentials is true if the Access-Control-Allow-Credentials header
+		// is expected.
+		Credentials bool
+		// Skip is the reason the test case is skipped if not empty.
+		Skip string
+	}
+)
+
+// nonMatchingOrigin is the origin used to test requests from an origin that
+// is not authorized.
+const nonMatchingOrigin = "https://cors-test.invalid"
+
+// serverCORSTest returns the file that tests the origin handlers generated in
+// the given HTTP server file or nil if the tests are not enabled for the
+// service.
+func serverCORSTest(f *codegen.File) *codegen.File {
+	if filepath.Base(f.Path) != "server.go" {
+		return nil
+	}
+	var svcData *ServiceData
+	for _, s := range f.Section("server-struct") {
+		if data, ok := s.Data.(*httpcodegen.ServiceData); ok {
+			svcData = ServicesData[data.Service.Name]
+		}
+	}
+	if svcData == nil || !expr.GenerateTests(svcData.Name) {
+		return nil
+	}
+	sections := []*codegen.SectionTemplate{
+		codegen.Header(svcData.Name+" HTTP server CORS tests", "server", []*codegen.ImportSpec{
+			{Path: "net/http"},
+			{Path: "net/http/httptest"},
+			{Path: "strings"},
+			{Path: "testing"},
+		}),
+		{
+			Name:    "cors-test",
+			Source:  corsTestT,
+			Data:    buildHarnessData(svcData.OriginHandler, svcData.Origins),
+			FuncMap: codegen.TemplateFuncs(),
+		},
+	}
+	for _, m := range svcData.Methods {
+		if m.Origins == nil {
+			// Same policies as the service
+			continue
+		}
+		sections = append(sections, &codegen.SectionTemplate{
+			Name:    "cors-test",
+			Source:  corsTestT,
+			Data:    buildHarnessData(m.OriginHandler, m.Origins),
+			FuncMap: codegen.TemplateFuncs(),
+		})
+	}
+	return &codegen.File{
+		Path:             filepath.Join(filepath.Dir(f.Path), "cors_test.go"),
+		SectionTemplates: sections,
+	}
+}
+
+// buildHarnessData builds the test cases of the origin handler applying the
+// given origins. The requests are made from a sample origin matching each
+// origin, origins whose sample is authorized by a previous origin are not
+// tested. The test cases of origin functions and of regular expressions for
+// which no sample can be generated are skipped.
+func buildHarnessData(handler string, origins []*expr.OriginExpr) *harnessData {
+	specs := make([]string, len(origins))
+	var hasFunc bool
+	for i, o := range origins {
+		switch {
+		case o.Func:
+			// The matcher skips empty origins.
+			hasFunc = true
+		case o.Regexp:
+			specs[i] = "/" + o.Origin + "/"
+		default:
+			specs[i] = o.Origin
+		}
+	}
+	m := newOriginMatcher(specs)
+	data := &harnessData{OriginHandler: handler}
+	for i, o := range origins {
+		method := "GET"
+		if o.Strict && len(o.Methods) > 0 && o.Methods[0] != "*" {
+			method = o.Methods[0]
+		}
+		sample, ok := sampleOrigin(o)
+		if !ok {
+			skip := fmt.Sprintf("cannot generate an origin of the form scheme://host matching the regular expression %q", o.Origin)
+			if o.Func {
+				skip = fmt.Sprintf("the origins authorized by the origin function %s are not known", o.Origin)
+			}
+			data.Cases = append(data.Cases, &harnessCaseData{
+				Name:   fmt.Sprintf("%s %s", method, o.Origin),
+				Method: method,
+				Skip:   skip,
+			})
+			continue
+		}
+		if m.match(sample) != i {
+			continue
+		}
+		data.Cases = append(data.Cases, &harnessCaseData{
+			Name:        fmt.Sprintf("%s %s", method, o.Origin),
+			Method:      method,
+			Origin:      sample,
+			AllowOrigin: sample,
+			Credentials: o.Credentials,
+		})
+		for _, verb := range o.Methods {
+			if verb == "*" {
+				continue
+			}
+			data.Cases = append(data.Cases, &harnessCaseData{
+				Name:          fmt.Sprintf("preflight %s %s", verb, o.Origin),
+				Method:        "OPTIONS",
+				Origin:        sample,
+				RequestMethod: verb,
+				AllowOrigin:   sample,
+				Credentials:   o.Credentials,
+			})
+		}
+	}
+	if !hasFunc && m.match(nonMatchingOrigin) < 0 {
+		// Origin functions may authorize any origin.
+		data.Cases = append(data.Cases, &harnessCaseData{
+			Name:   "GET non-matching origin",
+			Method: "GET",
+			Origin: nonMatchingOrigin,
+		})
+	}
+	return data
+}
+
+// sampleOrigin returns an origin matching the given origin expression. It
+// returns false for origin functions and for regular expressions for which no
+// sample can be generated.
+func sampleOrigin(o *expr.OriginExpr) (string, bool) {
+	switch {
+	case o.Func:
+		return "", false
+	case o.Regexp:
+		return sampleRegexp(o.Origin)
+	case o.Origin == "*":
+		return "https://cors-test.example", true
+	case strings.Contains(o.Origin, "://"):
+		sample := o.Origin
+		if strings.HasSuffix(sample, ":*") {
+			sample = strings.TrimSuffix(sample, "*") + "8080"
+		}
+		return strings.ReplaceAll(sample, "*", "example"), true
+	case strings.HasPrefix(o.Origin, "*"):
+		// Legacy wildcard origins without scheme match the origin prefix
+		// or suffix.
+		return "https://example" + strings.ReplaceAll(o.Origin[1:], "*", "example"), true
+	default:
+		return strings.ReplaceAll(o.Origin, "*", "example"), true
+	}
+}
+
+// sampleRegexp returns an origin matching the given regular expression built
+// by using the minimum number of repetitions and the first alternative and
+// character of alternations and character classes. The sample is prefixed with
+// the https scheme if it does not have one. It returns false if the regular
+// expression uses constructs not supported by the generator or if the sample
+// is not an origin of the form scheme://host matching the regular expression.
+func sampleRegexp(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var b strings.Builder
+	if !writeRegexpSample(&b, re) {
+		return "", false
+	}
+	sample := b.String()
+	if !strings.Contains(sample, "://") {
+		// e.g. "localhost" for .*localhost.*
+		sample = "https://" + sample
+	}
+	if _, host, _, ok := splitOrigin(sample); !ok || host == "" {
+		return "", false
+	}
+	if ok, _ := regexp.MatchString(pattern, sample); !ok {
+		return "", false
+	}
+	return sample, true
+}
+
+// writeRegexpSample writes a string matching re to b.
+func writeRegexpSample(b *strings.Builder, re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpStar, syntax.OpQuest:
+		return true
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		b.WriteRune(re.Rune[0])
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte('a')
+	case syntax.OpCapture, syntax.OpPlus, syntax.OpAlternate:
+		return writeRegexpSample(b, re.Sub[0])
+	case syntax.OpRepeat:
+		for i := 0; i < re.Min; i++ {
+			if !writeRegexpSample(b, re.Sub[0]) {
+				return false
+			}
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !writeRegexpSample(b, sub) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// Data: harnessData
+const corsTestT = `{{ printf "Test%s tests the CORS headers set by %s for each origin." .OriginHandler .OriginHandler | comment }}
+func Test{{ .OriginHandler }}(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Method        string
+		Origin        string
+		RequestMethod string
+		AllowOrigin   string
+		Credentials   bool
+		Skip          string
+	}{
+	{{- range .Cases }}
+		{ {{ printf "%q" .Name }}, {{ printf "%q" .Method }}, {{ printf "%q" .Origin }}, {{ printf "%q" .RequestMethod }}, {{ printf "%q" .AllowOrigin }}, {{ .Credentials }}, {{ printf "%q" .Skip }} },
+	{{- end }}
+	}
+	h := {{ .OriginHandler }}(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Skip != "" {
+				t.Skip(c.Skip)
+			}
+			r := httptest.NewRequest(c.Method, "/", nil)
+			r.Header.Set("Origin", c.Origin)
+			if c.RequestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", c.RequestMethod)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Errorf("got status %d, expected %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != c.AllowOrigin {
+				t.Errorf("got Access-Control-Allow-Origin %q, expected %q", got, c.AllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != c.Credentials {
+				t.Errorf("got Access-Control-Allow-Credentials %v, expected %v", got, c.Credentials)
+			}
+			if c.RequestMethod != "" {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, c.RequestMethod) {
+					t.Errorf("got Access-Control-Allow-Methods %q, expected to contain %q", got, c.RequestMethod)
+				}
+			}
+		})
+	}
+}
+`
@@ -0,0 +1,35 @@
+package cors
+
+import (
+	"testing"
+
+	"goa.design/plugins/v3/cors/expr"
+)
+
+func TestBuildHarnessData(t *testing.T) {
+	origins := []*expr.OriginExpr{
+		{Origin: "github.com/acme/tenants.IsAllowedOrigin", Func: true},
+		{Origin: "^https://(api|www)\\.goa\\.design$", Regexp: true},
+		{Origin: "\\bgoa\\b", Regexp: true},
+		{Origin: ".*localhost.*", Regexp: true},
+		{Origin: "^localhost$", Regexp: true},
+	}
+	expected := []*harnessCaseData{
+		{Name: "GET github.com/acme/tenants.IsAllowedOrigin", Method: "GET", Skip: "the origins authorized by the origin function github.com/acme/tenants.IsAllowedOrigin are not known"},
+		{Name: "GET ^https://(api|www)\\.goa\\.design$", Method: "GET", Origin: "https://api.goa.design", AllowOrigin: "https://api.goa.design"},
+		{Name: "GET \\bgoa\\b", Method: "GET", Skip: "cannot generate an origin of the form scheme://host matching the regular expression \"\\\\bgoa\\\\b\""},
+		{Name: "GET .*localhost.*", Method: "GET", Origin: "https://localhost", AllowOrigin: "https://localhost"},
+		{Name: "GET ^localhost$", Method: "GET", Skip: "cannot generate an origin of the form scheme://host matching the regular expression \"^localhost$\""},
+	}
+
+	data := buildHarnessData("HandleOrigin", origins)
+
+	if len(data.Cases) != len(expected) {
+		t.Fatalf("got %d cases, expected %d", len(data.Cases), len(expected))
+	}
+	for i, c := range expected {
+		if *data.Cases[i] != *c {
+			t.Errorf("got case %+v at index %d, expected %+v", *data.Cases[i], i, *c)
+		}
+	}
+}
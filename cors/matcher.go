@@ -0,0 +1,142 @@
+package cors
+
+import (
+	"regexp"
+	"strings"
+)
+
+type (
+	// originMatcher finds the first policy matching an origin without
+	// evaluating all the policy origins in turn. It indexes the policies by
+	// exact origin and the wildcard origins by scheme and by the part of the
+	// host following the last wildcard (e.g. "https" and "goa.design" for
+	// "https://*.goa.design") and falls back to evaluating the other origins
+	// in order.
+	originMatcher struct {
+		// exact indexes the plain origins, the value is the index of the
+		// first policy using the origin.
+		exact map[string]int
+		// patterns indexes the wildcard origins by scheme and host suffix.
+		patterns map[patternKey][]*patternOrigin
+		// others lists the regular expression and wildcard origins without
+		// scheme in policy order.
+		others []*otherOrigin
+		// any is the index of the first policy with origin "*" or -1.
+		any int
+	}
+
+	// patternKey is the key used to index wildcard origins.
+	patternKey struct {
+		scheme string
+		suffix string
+	}
+
+	// patternOrigin is an indexed wildcard origin.
+	patternOrigin struct {
+		index   int
+		pattern *originPattern
+	}
+
+	// otherOrigin is an origin that is not indexed.
+	otherOrigin struct {
+		index int
+		re    *regexp.Regexp
+		spec  string
+	}
+)
+
+// newOriginMatcher builds the matcher for the given policy origins. Origins
+// wrapped with "/" are compiled as regular expressions, newOriginMatcher
+// panics if such an origin is not a valid regular expression. Empty origins
+// never match.
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{
+		exact:    make(map[string]int),
+		patterns: make(map[patternKey][]*patternOrigin),
+		any:      -1,
+	}
+	for i, o := range origins {
+		switch {
+		case o == "":
+			// Policy with an origin function
+		case o == "*":
+			if m.any < 0 {
+				m.any = i
+			}
+		case isRegexpSpec(o):
+			re := regexp.MustCompile(strings.Trim(o, "/"))
+			m.others = append(m.others, &otherOrigin{index: i, re: re})
+		case !strings.Contains(o, "*"):
+			if _, ok := m.exact[o]; !ok {
+				m.exact[o] = i
+			}
+		default:
+			if p, ok := parseOriginPattern(o); ok {
+				key := patternKey{scheme: p.scheme, suffix: p.suffix}
+				m.patterns[key] = append(m.patterns[key], &patternOrigin{index: i, pattern: p})
+				continue
+			}
+			m.others = append(m.others, &otherOrigin{index: i, spec: o})
+		}
+	}
+	return m
+}
+
+// match returns the index of the first policy whose origin matches the given
+// origin or -1 if there is none.
+func (m *originMatcher) match(origin string) int {
+	best := m.any
+	if i, ok := m.exact[origin]; ok && (best < 0 || i < best) {
+		best = i
+	}
+	if len(m.patterns) > 0 {
+		if scheme, host, port, ok := splitOrigin(origin); ok {
+			// Lookup the patterns whose host suffix is the whole host, any
+			// of the host label suffixes or empty.
+			for i := -1; i < len(host); i++ {
+				if i >= 0 && host[i] != '.' {
+					continue
+				}
+				for _, p := range m.patterns[patternKey{scheme: scheme, suffix: host[i+1:]}] {
+					if best >= 0 && p.index >= best {
+						break
+					}
+					if p.pattern.match(scheme, host, port) {
+						best = p.index
+						break
+					}
+				}
+			}
+			for _, p := range m.patterns[patternKey{scheme: scheme}] {
+				if best >= 0 && p.index >= best {
+					break
+				}
+				if p.pattern.match(scheme, host, port) {
+					best = p.index
+					break
+				}
+			}
+		}
+	}
+	for _, o := range m.others {
+		if best >= 0 && o.index >= best {
+			break
+		}
+		if o.re != nil {
+			if MatchOriginRegexp(origin, o.re) {
+				return o.index
+			}
+			continue
+		}
+		if MatchOrigin(origin, o.spec) {
+			return o.index
+		}
+	}
+	return best
+}
+
+// isRegexpSpec returns true if the origin specification is a regular
+// expression wrapped with "/".
+func isRegexpSpec(spec string) bool {
+	return len(spec) > 1 && strings.HasPrefix(spec, "/") && strings.HasSuffix(spec, "/")
+}
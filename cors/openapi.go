@@ -0,0 +1,339 @@
+package cors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"goa.design/goa/v3/codegen"
+	"goa.design/goa/v3/eval"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/cors/expr"
+)
+
+// OpenAPIExtension is the name of the OpenAPI vendor extension that describes
+// the CORS policies in the OpenAPI specifications generated by Goa.
+const OpenAPIExtension = "x-cors"
+
+// PolicyExtension is the value of the OpenAPI extension for a single origin.
+type PolicyExtension struct {
+	// Origin is the origin string.
+	Origin string `json:"origin"`
+	// Regexp is true if Origin is a regular expression.
+	Regexp bool `json:"regexp,omitempty"`
+	// Func is true if Origin identifies a Go function authorizing origins.
+	Func bool `json:"func,omitempty"`
+	// Methods is the list of authorized HTTP methods.
+	Methods []string `json:"methods,omitempty"`
+	// Headers is the list of authorized headers.
+	Headers []string `json:"headers,omitempty"`
+	// Exposed is the list of headers exposed to clients.
+	Exposed []string `json:"exposed,omitempty"`
+	// MaxAge is the duration in seconds to cache a preflight response.
+	MaxAge uint `json:"maxAge,omitempty"`
+	// Credentials is true if credentials are allowed.
+	Credentials bool `json:"credentials,omitempty"`
+	// PrivateNetwork is true if Private Network Access is allowed.
+	PrivateNetwork bool `json:"privateNetwork,omitempty"`
+	// Strict is true if unauthorized preflight requests are rejected.
+	Strict bool `json:"strict,omitempty"`
+	// GRPCWeb is true if gRPC-Web clients are authorized.
+	GRPCWeb bool `json:"grpcWeb,omitempty"`
+}
+
+// specExtension is a x-cors extension of an OpenAPI specification object.
+type specExtension struct {
+	// extensions holds the extensions of the object.
+	extensions map[string]interface{}
+	// path is the list of keys leading to the object in the specification.
+	path []string
+	// value is the value of the extension.
+	value interface{}
+}
+
+// Prepare adds the x-cors extension describing the CORS policies to the API
+// and to the HTTP operations of the design so that they are included in the
+// generated OpenAPI specifications. The API extension lists the API level
+// policies, the operation extensions list the policies that apply to each
+// endpoint including the operations of the OPTIONS routes defined in the
+// design. Prepare does not override extensions defined in the design.
+func Prepare(genpkg string, roots []eval.Root) error {
+	for _, root := range roots {
+		r, ok := root.(*goaexpr.RootExpr)
+		if !ok {
+			continue
+		}
+		if r.API == nil {
+			continue
+		}
+		// There is no service with an empty name so Origins returns the API
+		// level origins.
+		if err := addExtension(&r.API.Meta, expr.Origins("")); err != nil {
+			return err
+		}
+		if r.API.HTTP == nil {
+			continue
+		}
+		for _, svc := range r.API.HTTP.Services {
+			origins := expr.Origins(svc.Name())
+			for _, e := range svc.HTTPEndpoints {
+				eorigins := origins
+				if mo := expr.MethodOrigins(svc.Name(), e.MethodExpr.Name); mo != nil {
+					eorigins = mo
+				}
+				if err := addExtension(&e.MethodExpr.Meta, eorigins); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// PolicyExtensions returns the values of the OpenAPI extension describing the
+// given origins.
+func PolicyExtensions(origins []*expr.OriginExpr) []*PolicyExtension {
+	exts := make([]*PolicyExtension, len(origins))
+	for i, o := range origins {
+		exts[i] = &PolicyExtension{
+			Origin:         o.Origin,
+			Regexp:         o.Regexp,
+			Func:           o.Func,
+			Methods:        o.Methods,
+			Headers:        o.Headers,
+			Exposed:        o.Exposed,
+			MaxAge:         o.MaxAge,
+			Credentials:    o.Credentials,
+			PrivateNetwork: o.PrivateNetwork,
+			Strict:         o.Strict,
+			GRPCWeb:        o.GRPCWeb,
+		}
+	}
+	return exts
+}
+
+// addExtension sets the OpenAPI extension describing the given origins in the
+// given meta unless the design already defines it.
+func addExtension(meta *goaexpr.MetaExpr, origins []*expr.OriginExpr) error {
+	if len(origins) == 0 {
+		return nil
+	}
+	key := "openapi:extension:" + OpenAPIExtension
+	if _, ok := (*meta)[key]; ok {
+		return nil
+	}
+	if _, ok := (*meta)["swagger:extension:"+OpenAPIExtension]; ok {
+		return nil
+	}
+	b, err := json.Marshal(PolicyExtensions(origins))
+	if err != nil {
+		return err
+	}
+	if *meta == nil {
+		*meta = goaexpr.MetaExpr{}
+	}
+	(*meta)[key] = []string{string(b)}
+	return nil
+}
+
+// openapiCORS makes the given OpenAPI specification file render the x-cors
+// extensions after the keys generated by Goa. Goa renders the objects that
+// have extensions with sorted keys, the extensions are thus removed from the
+// specification while it is rendered and added back to the rendered document.
+func openapiCORS(f *codegen.File) {
+	if filepath.Ext(f.Path) != ".json" && filepath.Ext(f.Path) != ".yaml" {
+		return
+	}
+	if !strings.HasPrefix(filepath.Base(f.Path), "openapi") {
+		return
+	}
+	for _, s := range f.SectionTemplates {
+		if !strings.HasPrefix(s.Name, "openapi") {
+			continue
+		}
+		if _, ok := s.FuncMap["toJSON"]; ok {
+			s.FuncMap["toJSON"] = func(spec interface{}) (string, error) { return renderSpec(spec, false) }
+		}
+		if _, ok := s.FuncMap["toYAML"]; ok {
+			s.FuncMap["toYAML"] = func(spec interface{}) (string, error) { return renderSpec(spec, true) }
+		}
+	}
+}
+
+// renderSpec renders the given OpenAPI specification in YAML if asYAML is true
+// and in JSON otherwise, the x-cors extensions come last in their objects.
+func renderSpec(spec interface{}, asYAML bool) (string, error) {
+	exts := cutExtensions(spec)
+	defer func() {
+		for _, e := range exts {
+			e.extensions[OpenAPIExtension] = e.value
+		}
+	}()
+	marshal := json.Marshal
+	if asYAML {
+		marshal = yaml.Marshal
+	}
+	b, err := marshal(spec)
+	if err != nil {
+		return "", fmt.Errorf("cors: %w", err)
+	}
+	if len(exts) == 0 {
+		return string(b), nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return "", fmt.Errorf("cors: %w", err)
+	}
+	for _, e := range exts {
+		if err := addNodeExtension(&doc, e); err != nil {
+			return "", err
+		}
+	}
+	if asYAML {
+		b, err := yaml.Marshal(&doc)
+		if err != nil {
+			return "", fmt.Errorf("cors: %w", err)
+		}
+		return string(b), nil
+	}
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, &doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// cutExtensions removes the x-cors extensions from the info object and from
+// the operations of the given OpenAPI specification and returns them.
+func cutExtensions(spec interface{}) []*specExtension {
+	v := indirect(reflect.ValueOf(spec))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var exts []*specExtension
+	if e := cutExtension(v.FieldByName("Info"), "info"); e != nil {
+		exts = append(exts, e)
+	}
+	paths := v.FieldByName("Paths")
+	if paths.Kind() != reflect.Map {
+		return exts
+	}
+	iter := paths.MapRange()
+	for iter.Next() {
+		item := indirect(iter.Value())
+		if item.Kind() != reflect.Struct {
+			continue
+		}
+		for i := 0; i < item.NumField(); i++ {
+			name := strings.Split(item.Type().Field(i).Tag.Get("json"), ",")[0]
+			if e := cutExtension(item.Field(i), "paths", iter.Key().String(), name); e != nil {
+				exts = append(exts, e)
+			}
+		}
+	}
+	return exts
+}
+
+// cutExtension removes the x-cors extension from the extensions of the given
+// object and returns it, it returns nil if the object has no x-cors extension.
+func cutExtension(v reflect.Value, path ...string) *specExtension {
+	v = indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	exts, ok := v.FieldByName("Extensions").Interface().(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	val, ok := exts[OpenAPIExtension]
+	if !ok {
+		return nil
+	}
+	delete(exts, OpenAPIExtension)
+	return &specExtension{extensions: exts, path: path, value: val}
+}
+
+// indirect dereferences the given pointers and interfaces.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// addNodeExtension adds the given extension to the object of the rendered
+// document it belongs to.
+func addNodeExtension(doc *yaml.Node, e *specExtension) error {
+	n := doc.Content[0]
+	for _, key := range e.path {
+		var found *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				found = n.Content[i+1]
+				break
+			}
+		}
+		if found == nil {
+			return fmt.Errorf("cors: cannot find OpenAPI object %q", strings.Join(e.path, "."))
+		}
+		n = found
+	}
+	if n.Kind != yaml.MappingNode {
+		return fmt.Errorf("cors: OpenAPI object %q is not a mapping", strings.Join(e.path, "."))
+	}
+	var val yaml.Node
+	if err := val.Encode(e.value); err != nil {
+		return fmt.Errorf("cors: %w", err)
+	}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: OpenAPIExtension}, &val)
+	return nil
+}
+
+// writeJSON writes the JSON encoding of the given document node to b, it is
+// the inverse of decoding a JSON document with yaml.Unmarshal.
+func writeJSON(b *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return writeJSON(b, n.Content[0])
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			k, _ := json.Marshal(n.Content[i].Value)
+			b.Write(k)
+			b.WriteByte(':')
+			if err := writeJSON(b, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSON(b, c); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		if n.ShortTag() == "!!str" {
+			s, _ := json.Marshal(n.Value)
+			b.Write(s)
+			return nil
+		}
+		b.WriteString(n.Value)
+	default:
+		return fmt.Errorf("cors: unexpected YAML node kind %d in OpenAPI specification", n.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package cors_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"goa.design/goa/v3/eval"
+	"goa.design/goa/v3/expr"
+	httpcodegen "goa.design/goa/v3/http/codegen"
+	openapiv2 "goa.design/goa/v3/http/codegen/openapi/v2"
+	openapiv3 "goa.design/goa/v3/http/codegen/openapi/v3"
+	"goa.design/plugins/v3/cors"
+	corsexpr "goa.design/plugins/v3/cors/expr"
+	"goa.design/plugins/v3/cors/testdata"
+)
+
+func TestPrepare(t *testing.T) {
+	apiOrigins := corsexpr.Root.APIOrigins
+	defer func() { corsexpr.Root.APIOrigins = apiOrigins }()
+	corsexpr.Root.APIOrigins = map[string]*corsexpr.OriginExpr{}
+
+	httpcodegen.RunHTTPDSL(t, testdata.OpenAPIExtensionDSL)
+	if err := cors.Prepare("", []eval.Root{expr.Root}); err != nil {
+		t.Fatal(err)
+	}
+	key := "openapi:extension:" + cors.OpenAPIExtension
+	cases := []struct {
+		Name     string
+		Meta     expr.MetaExpr
+		Expected string
+	}{
+		{"api", expr.Root.API.Meta, `[{"origin":"https://api.goa.design"}]`},
+		{"service", expr.Root.API.HTTP.Services[0].HTTPEndpoints[0].MethodExpr.Meta, `[{"origin":".*goa.design","regexp":true,"methods":["GET"],"maxAge":600},{"origin":"https://api.goa.design"}]`},
+		{"method", expr.Root.API.HTTP.Services[0].HTTPEndpoints[1].MethodExpr.Meta, `[{"origin":"https://admin.goa.design","methods":["DELETE"],"credentials":true}]`},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			v, ok := c.Meta[key]
+			if !ok {
+				t.Fatalf("extension not found")
+			}
+			if len(v) != 1 || v[0] != c.Expected {
+				t.Errorf("got %v, expected %s", v, c.Expected)
+			}
+		})
+	}
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	apiOrigins := corsexpr.Root.APIOrigins
+	defer func() { corsexpr.Root.APIOrigins = apiOrigins }()
+	corsexpr.Root.APIOrigins = map[string]*corsexpr.OriginExpr{}
+
+	httpcodegen.RunHTTPDSL(t, testdata.OpenAPIExtensionDSL)
+	if err := cors.Prepare("", []eval.Root{expr.Root}); err != nil {
+		t.Fatal(err)
+	}
+	v2, err := openapiv2.Files(expr.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v3, err := openapiv3.Files(expr.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs, err := cors.Generate("", []eval.Root{expr.Root}, append(v2, v3...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"openapi.json": {
+			`"info":{"title":"","version":"","x-cors":[{"origin":"https://api.goa.design"}]}`,
+			`"operationId":"OpenAPIExtension#OpenAPIExtensionOptions","responses":{"204":{"description":"No Content response."}},"schemes":["http"],"x-cors":[{"maxAge":600,"methods":["GET"],"origin":".*goa.design","regexp":true},{"origin":"https://api.goa.design"}]}`,
+		},
+		"openapi3.json": {
+			`"info":{"title":"Goa API","version":"1.0","x-cors":[{"origin":"https://api.goa.design"}]}`,
+			`"operationId":"OpenAPIExtension#OpenAPIExtensionDelete","responses":{"204":{"description":"No Content response."}},"x-cors":[{"credentials":true,"methods":["DELETE"],"origin":"https://admin.goa.design"}]}`,
+		},
+		"openapi3.yaml": {
+			"info:\n    title: Goa API\n    version: \"1.0\"\n    x-cors:\n        - origin: https://api.goa.design\n",
+		},
+	}
+	for _, f := range fs {
+		name := filepath.Base(f.Path)
+		t.Run(name, func(t *testing.T) {
+			render := func() string {
+				var buf bytes.Buffer
+				for _, s := range f.SectionTemplates {
+					tmpl := template.Must(template.New(s.Name).Funcs(s.FuncMap).Parse(s.Source))
+					if err := tmpl.Execute(&buf, s.Data); err != nil {
+						t.Fatal(err)
+					}
+				}
+				return buf.String()
+			}
+			code := render()
+			for _, e := range expected[name] {
+				if !strings.Contains(code, e) {
+					t.Errorf("got\n%s\nexpected to contain\n%s", code, e)
+				}
+			}
+			// The extensions are restored once the specification is rendered.
+			if again := render(); again != code {
+				t.Errorf("got\n%s\nwhen rendering again, expected\n%s", again, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package cors
+
+import (
+	"strings"
+)
+
+// originPattern is a parsed origin specification of the form
+// scheme://host[:port] where the host labels may contain wildcards and the
+// port may be a wildcard.
+type originPattern struct {
+	// scheme is the lower case origin scheme.
+	scheme string
+	// labels lists the lower case host labels, a "*" in a label matches
+	// any sequence of characters within the label.
+	labels []string
+	// anyHost is true if the host is "*", the pattern then matches any host
+	// with the scheme and port regardless of its number of labels.
+	anyHost bool
+	// port is the origin port, empty if the origin must not specify a port
+	// and "*" if any port (or none) is authorized.
+	port string
+	// suffix is the part of the host following the last label that contains
+	// a wildcard, it is used to index patterns.
+	suffix string
+}
+
+// parseOriginPattern parses the given origin specification. It returns false
+// if the specification does not start with a scheme.
+func parseOriginPattern(spec string) (*originPattern, bool) {
+	scheme, host, port, ok := splitOrigin(spec)
+	if !ok || strings.Contains(scheme, "*") {
+		return nil, false
+	}
+	p := &originPattern{
+		scheme:  scheme,
+		labels:  strings.Split(host, "."),
+		port:    port,
+		anyHost: host == "*",
+	}
+	last := -1
+	for i, l := range p.labels {
+		if strings.Contains(l, "*") {
+			last = i
+		}
+	}
+	p.suffix = strings.Join(p.labels[last+1:], ".")
+	return p, true
+}
+
+// splitOrigin splits an origin into its lower case scheme and host and its
+// port. It returns false if the origin does not start with a scheme.
+func splitOrigin(origin string) (scheme, host, port string, ok bool) {
+	i := strings.Index(origin, "://")
+	if i <= 0 {
+		return "", "", "", false
+	}
+	scheme = strings.ToLower(origin[:i])
+	host = origin[i+3:]
+	if strings.HasPrefix(host, "[") {
+		// IPv6 literal
+		if j := strings.Index(host, "]"); j > 0 {
+			if rest := host[j+1:]; strings.HasPrefix(rest, ":") {
+				port = rest[1:]
+			}
+			host = host[:j+1]
+		}
+	} else if j := strings.LastIndex(host, ":"); j >= 0 {
+		port = host[j+1:]
+		host = host[:j]
+	}
+	return scheme, strings.ToLower(host), port, true
+}
+
+// match returns true if the origin made of the given lower case scheme and
+// host and port matches the pattern.
+func (p *originPattern) match(scheme, host, port string) bool {
+	if scheme != p.scheme {
+		return false
+	}
+	if p.port != "*" && port != p.port {
+		return false
+	}
+	if p.anyHost {
+		return host != ""
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) != len(p.labels) {
+		return false
+	}
+	for i, l := range labels {
+		if !matchLabel(l, p.labels[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOrigin returns true if the given origin matches the pattern.
+func (p *originPattern) matchOrigin(origin string) bool {
+	scheme, host, port, ok := splitOrigin(origin)
+	if !ok {
+		return false
+	}
+	return p.match(scheme, host, port)
+}
+
+// matchLabel returns true if the given host label matches the pattern. A "*"
+// in the pattern matches any sequence of characters, a label is never empty.
+func matchLabel(label, pattern string) bool {
+	if label == "" {
+		return false
+	}
+	if !strings.Contains(pattern, "*") {
+		return label == pattern
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(label, parts[0]) {
+		return false
+	}
+	label = label[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(label, part)
+		if i < 0 {
+			return false
+		}
+		label = label[i+len(part):]
+	}
+	return strings.HasSuffix(label, last)
+}
@@ -0,0 +1,704 @@
+package cors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// Policy describes a CORS policy applied at runtime.
+	Policy struct {
+		// Origin is the origin specification as accepted by MatchOrigin,
+		// regular expressions are wrapped with "/".
+		Origin string
+		// OriginFunc authorizes the request origins in place of Origin when
+		// not nil.
+		OriginFunc func(r *http.Request) bool
+		// Methods is the list of authorized HTTP methods.
+		Methods []string
+		// Exposed is the list of headers exposed to clients.
+		Exposed []string
+		// Headers is the list of authorized headers, "*" authorizes all.
+		Headers []string
+		// MaxAge is the duration in seconds to cache a preflight request
+		// response.
+		MaxAge uint
+		// Credentials sets Access-Control-Allow-Credentials header in the
+		// response.
+		Credentials bool
+		// PrivateNetwork sets Access-Control-Allow-Private-Network header in
+		// the response to Private Network Access preflight requests.
+		PrivateNetwork bool
+		// Strict rejects preflight requests for methods or headers that are
+		// not authorized by the policy.
+		Strict bool
+		// GRPCWeb authorizes the request headers sent by gRPC-Web clients
+		// and exposes the gRPC response headers.
+		GRPCWeb bool
+	}
+
+	// PolicyProvider provides the CORS policies applied by the generated
+	// origin handlers at runtime, see WithPolicyProvider.
+	PolicyProvider interface {
+		// Policies returns the policies that apply to the given service
+		// method. method is empty for the service level policies. A nil
+		// result means the policies defined in the design apply, the
+		// methods that do not define policies of their own in the design
+		// use the service level policies in this case.
+		Policies(service, method string) []Policy
+	}
+
+	// Option configures the origin handlers, see OriginHandler.
+	Option func(*options)
+
+	// PolicyStore is a PolicyProvider whose policies can be replaced safely
+	// while requests are being served. The zero value is ready to use and
+	// does not override the policies defined in the design.
+	PolicyStore struct {
+		// mu serializes the writers.
+		mu sync.Mutex
+		// snapshot holds the current *policySnapshot.
+		snapshot atomic.Value
+	}
+
+	// Observer is notified of the CORS requests served by the origin
+	// handlers, e.g. to log or count the requests made from unauthorized
+	// origins.
+	Observer interface {
+		// ObserveOrigin is called for each request with an Origin header
+		// before the response is written. policy is the policy that matches
+		// the origin or nil if there is none and preflight is true for
+		// preflight requests. ObserveOrigin must not modify the policy.
+		ObserveOrigin(r *http.Request, origin string, policy *Policy, preflight bool)
+	}
+
+	// ObserverFunc is an adapter to allow the use of ordinary functions as
+	// observers.
+	ObserverFunc func(r *http.Request, origin string, policy *Policy, preflight bool)
+
+	// policySet is a list of policies evaluated in order together with the
+	// matcher used to find the policy that applies to a request.
+	policySet struct {
+		policies []Policy
+		// files lists the file server variants of the policies, see
+		// WithFileServer.
+		files   []Policy
+		matcher *originMatcher
+		// funcs lists the indices of the policies with an OriginFunc in
+		// order.
+		funcs []int
+		// strict is true if any of the policies is strict.
+		strict bool
+	}
+
+	// options holds the configuration of an origin handler.
+	options struct {
+		provider PolicyProvider
+		observer Observer
+	}
+
+	// contextKey is the type of the context keys set by the CORS handlers.
+	contextKey int
+
+	// policySnapshot is an immutable set of policies held by a PolicyStore.
+	policySnapshot struct {
+		policies []Policy
+		methods  map[string][]Policy
+	}
+)
+
+// Handler returns a HTTP middleware that applies the given CORS policies. The
+// policies are evaluated in order and the first policy whose origin matches
+// the request Origin header applies. Origins wrapped with "/" are compiled
+// once as regular expressions, Handler panics if such an origin is not a valid
+// regular expression.
+func Handler(policies ...Policy) func(http.Handler) http.Handler {
+	ps := compilePolicies(copyPolicies(policies))
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ps.serve(w, r, h)
+		})
+	}
+}
+
+// OriginHandler returns the HTTP middleware used by the generated origin
+// handlers of the given service method, method is empty for the service level
+// handlers. policies are the policies defined in the design, they apply unless
+// the provider given with WithPolicyProvider returns policies for the service
+// method.
+func OriginHandler(service, method string, policies []Policy, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(opts)
+	ps := compilePolicies(copyPolicies(policies))
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = o.observe(r)
+			if policies := o.policies(service, method); policies != nil {
+				Apply(w, r, h, policies)
+				return
+			}
+			ps.serve(w, r, h)
+		})
+	}
+}
+
+// MethodHandler returns the HTTP middleware used by the generated origin
+// handlers of the methods that do not define policies of their own in the
+// design. The middleware applies the policies returned by the provider given
+// with WithPolicyProvider for the service method if any and serves the request
+// with the service level origin handler built by inherit otherwise.
+func MethodHandler(service, method string, inherit func(http.Handler, ...Option) http.Handler, opts ...Option) func(http.Handler) http.Handler {
+	o := newOptions(opts)
+	return func(h http.Handler) http.Handler {
+		inherited := inherit(h, opts...)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if policies := o.policies(service, method); policies != nil {
+				Apply(w, o.observe(r), h, policies)
+				return
+			}
+			inherited.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithPolicyProvider returns an option that makes the origin handlers apply the
+// policies returned by p in place of the policies defined in the design. Use a
+// PolicyStore to update the policies while requests are being served.
+func WithPolicyProvider(p PolicyProvider) Option {
+	return func(o *options) {
+		o.provider = p
+	}
+}
+
+// WithObserver returns an option that makes the origin handlers notify obs of
+// the CORS requests they serve.
+func WithObserver(obs Observer) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// Apply sets the CORS response headers corresponding to the first policy
+// whose origin matches the request Origin header and calls h. Requests without
+// an Origin header are passed to h unchanged. Apply caches the origin matchers
+// it builds by policy contents so that providers may return new slices on each
+// call without recompiling the policies.
+func Apply(w http.ResponseWriter, r *http.Request, h http.Handler, policies []Policy) {
+	if len(policies) == 0 {
+		compilePolicies(nil).serve(w, r, h)
+		return
+	}
+	key := policiesKey(policies)
+	applyCache.RLock()
+	ps, ok := applyCache.sets[key]
+	applyCache.RUnlock()
+	if !ok {
+		ps = compilePolicies(policies)
+		applyCache.Lock()
+		if len(applyCache.sets) >= maxApplyCache {
+			applyCache.sets = make(map[string]*policySet)
+		}
+		applyCache.sets[key] = ps
+		applyCache.Unlock()
+	} else if len(ps.funcs) > 0 {
+		// Origin functions are not part of the key.
+		ps = ps.withFuncs(policies)
+	}
+	ps.serve(w, r, h)
+}
+
+const (
+	// originAuthorizedKey is the request context key set to true when the
+	// request origin is authorized by a CORS policy.
+	originAuthorizedKey contextKey = iota + 1
+	// observerKey is the request context key holding the Observer.
+	observerKey
+	// fileServerKey is the request context key set to true for the
+	// requests made to file servers.
+	fileServerKey
+)
+
+var (
+	// grpcWebHeaders lists the request headers sent by gRPC-Web clients.
+	grpcWebHeaders = []string{"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"}
+	// grpcWebExposed lists the gRPC response headers read by gRPC-Web
+	// clients.
+	grpcWebExposed = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+	// fileServerHeaders lists the conditional and range request headers
+	// sent to file servers.
+	fileServerHeaders = []string{"Range", "If-Range", "If-None-Match", "If-Modified-Since"}
+	// fileServerExposed lists the file server response headers describing
+	// partial and cached content.
+	fileServerExposed = []string{"Accept-Ranges", "Content-Range", "Content-Length", "ETag", "Last-Modified"}
+)
+
+// maxApplyCache is the maximum number of policy sets cached by Apply.
+const maxApplyCache = 64
+
+// applyCache caches the policy sets built by Apply.
+var applyCache = struct {
+	sync.RWMutex
+	sets map[string]*policySet
+}{sets: make(map[string]*policySet)}
+
+// newOptions returns the configuration set by the given options.
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// policies returns the policies returned by the provider for the given service
+// method or nil if there is no provider.
+func (o *options) policies(service, method string) []Policy {
+	if o.provider == nil {
+		return nil
+	}
+	return o.provider.Policies(service, method)
+}
+
+// observe returns the request whose context holds the observer if any.
+func (o *options) observe(r *http.Request) *http.Request {
+	if o.observer == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), observerKey, o.observer))
+}
+
+// policiesKey returns the key of the given policies in the Apply cache. The key
+// is made of the policy contents except for the origin functions.
+func policiesKey(policies []Policy) string {
+	var b strings.Builder
+	for _, p := range policies {
+		fmt.Fprintf(&b, "%q %t %q %q %q %d %t %t %t %t\n", p.Origin, p.OriginFunc != nil,
+			p.Methods, p.Exposed, p.Headers, p.MaxAge, p.Credentials, p.PrivateNetwork, p.Strict, p.GRPCWeb)
+	}
+	return b.String()
+}
+
+// compilePolicies returns the policy set corresponding to the given policies.
+func compilePolicies(policies []Policy) *policySet {
+	ps := &policySet{policies: make([]Policy, len(policies)), files: make([]Policy, len(policies))}
+	origins := make([]string, len(policies))
+	for i, p := range policies {
+		if p.GRPCWeb {
+			p = p.withGRPCWeb()
+		}
+		ps.policies[i] = p
+		ps.files[i] = p.withFileServer()
+		ps.strict = ps.strict || p.Strict
+		if p.OriginFunc != nil {
+			// Origin is ignored, the matcher skips empty origins.
+			ps.funcs = append(ps.funcs, i)
+			continue
+		}
+		origins[i] = p.Origin
+	}
+	ps.matcher = newOriginMatcher(origins)
+	return ps
+}
+
+// withFuncs returns a copy of the policy set that uses the origin functions of
+// the given policies, policies must have the same contents as the policies of
+// the set otherwise.
+func (ps *policySet) withFuncs(policies []Policy) *policySet {
+	res := *ps
+	res.policies = make([]Policy, len(ps.policies))
+	res.files = make([]Policy, len(ps.files))
+	copy(res.policies, ps.policies)
+	copy(res.files, ps.files)
+	for _, i := range ps.funcs {
+		res.policies[i].OriginFunc = policies[i].OriginFunc
+		res.files[i].OriginFunc = policies[i].OriginFunc
+	}
+	return &res
+}
+
+// match returns the index of the first policy that authorizes the request
+// origin or -1 if there is none.
+func (ps *policySet) match(r *http.Request, origin string) int {
+	i := ps.matcher.match(origin)
+	for _, j := range ps.funcs {
+		if i >= 0 && j >= i {
+			break
+		}
+		if ps.policies[j].OriginFunc(r) {
+			return j
+		}
+	}
+	return i
+}
+
+// serve sets the CORS response headers corresponding to the first policy
+// whose origin matches the request Origin header and calls h.
+func (ps *policySet) serve(w http.ResponseWriter, r *http.Request, h http.Handler) {
+	// The response depends on the Origin header whether it matches or not,
+	// and preflight responses also depend on the requested method and headers.
+	AddVary(w.Header(), "Origin")
+	if r.Method == http.MethodOptions {
+		AddVary(w.Header(), "Access-Control-Request-Method", "Access-Control-Request-Headers")
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Not a CORS request
+		h.ServeHTTP(w, r)
+		return
+	}
+	acrm := r.Header.Get("Access-Control-Request-Method")
+	i := ps.match(r, origin)
+	if obs, ok := r.Context().Value(observerKey).(Observer); ok {
+		var p *Policy
+		if i >= 0 {
+			cp := ps.policies[i]
+			if isFileServerRequest(r) {
+				cp = ps.files[i]
+			}
+			p = &cp
+		}
+		obs.ObserveOrigin(r, origin, p, r.Method == http.MethodOptions && acrm != "")
+	}
+	if isWebSocketUpgrade(r) {
+		// Browsers do not apply CORS to WebSocket connections, reject the
+		// upgrade requests from unauthorized origins instead.
+		if i < 0 && !isSameOrigin(r, origin) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), originAuthorizedKey, true)))
+		return
+	}
+	if i >= 0 {
+		p := &ps.policies[i]
+		if isFileServerRequest(r) {
+			p = &ps.files[i]
+		}
+		if p.Strict {
+			if acrm != "" {
+				if !MatchMethod(acrm, p.Methods...) ||
+					!MatchHeaders(r.Header.Get("Access-Control-Request-Headers"), p.Headers...) ||
+					(isPrivateNetworkRequest(r) && !p.PrivateNetwork) {
+					// Reject preflight request for unauthorized method, headers
+					// or private network access
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+			} else if !MatchMethod(r.Method, p.Methods...) {
+				// Do not set CORS headers for unauthorized method
+				h.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if len(p.Exposed) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(p.Exposed, ", "))
+		}
+		if p.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.FormatUint(uint64(p.MaxAge), 10))
+		}
+		if p.Credentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if acrm != "" {
+			// We are handling a preflight request
+			if p.MaxAge > 0 {
+				// Let HTTP caches keep the preflight response as long as
+				// browsers do.
+				w.Header().Set("Cache-Control", "max-age="+strconv.FormatUint(uint64(p.MaxAge), 10))
+			}
+			if len(p.Methods) > 0 {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.Methods, ", "))
+			}
+			if len(p.Headers) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.Headers, ", "))
+			}
+			if p.PrivateNetwork && isPrivateNetworkRequest(r) {
+				w.Header().Set("Access-Control-Allow-Private-Network", "true")
+			}
+		}
+		h.ServeHTTP(w, r)
+		return
+	}
+	if ps.strict && acrm != "" {
+		// Reject preflight request from unauthorized origin
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// EndPreflight returns a handler that responds to the preflight requests
+// authorized by the enclosing origin handler with 200 OK and passes the other
+// requests to h. It is meant to wrap handlers that do not serve OPTIONS
+// requests such as the gRPC-Web handlers, the generated gRPC origin handlers
+// use it.
+func EndPreflight(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" &&
+			w.Header().Get("Access-Control-Allow-Origin") != "" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ObserveOrigin calls f(r, origin, policy, preflight).
+func (f ObserverFunc) ObserveOrigin(r *http.Request, origin string, policy *Policy, preflight bool) {
+	f(r, origin, policy, preflight)
+}
+
+// WithFileServer returns a copy of ctx that makes the CORS handlers apply the
+// file server variant of the policies to the request: the conditional and
+// range request headers (Range, If-Range, If-None-Match and If-Modified-Since)
+// are authorized and the headers describing partial and cached content
+// (Accept-Ranges, Content-Range, Content-Length, ETag and Last-Modified) are
+// exposed so that browsers can use the 206 Partial Content and 304 Not
+// Modified responses of the file servers. The generated file server handlers
+// use WithFileServer for both the actual and the preflight requests.
+func WithFileServer(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fileServerKey, true)
+}
+
+// CheckWebSocketOrigin returns true if the origin of the given WebSocket
+// upgrade request is authorized by the CORS policies of the handler that
+// served the request, if the request has no Origin header or if the origin is
+// the same as the request host. It is meant to be used as the CheckOrigin
+// function of the WebSocket upgrader given to the generated servers, e.g.:
+//
+//	upgrader := &websocket.Upgrader{CheckOrigin: cors.CheckWebSocketOrigin}
+func CheckWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if ok, _ := r.Context().Value(originAuthorizedKey).(bool); ok {
+		return true
+	}
+	return isSameOrigin(r, origin)
+}
+
+// withGRPCWeb returns a copy of the policy that authorizes the gRPC-Web
+// request headers and POST method and exposes the gRPC response headers.
+func (p Policy) withGRPCWeb() Policy {
+	if len(p.Methods) > 0 {
+		p.Methods = mergeNames(p.Methods, "POST")
+	}
+	p.Headers = mergeNames(p.Headers, grpcWebHeaders...)
+	p.Exposed = mergeNames(p.Exposed, grpcWebExposed...)
+	return p
+}
+
+// withFileServer returns a copy of the policy that authorizes the conditional
+// and range request headers, the GET and HEAD methods and exposes the headers
+// describing partial and cached content.
+func (p Policy) withFileServer() Policy {
+	if len(p.Methods) > 0 {
+		p.Methods = mergeNames(p.Methods, "GET", "HEAD")
+	}
+	p.Headers = mergeNames(p.Headers, fileServerHeaders...)
+	p.Exposed = mergeNames(p.Exposed, fileServerExposed...)
+	return p
+}
+
+// mergeNames returns a new slice containing names followed by the values that
+// are not in names. Names are compared case insensitively and "*" contains
+// all names.
+func mergeNames(names []string, values ...string) []string {
+	res := make([]string, len(names), len(names)+len(values))
+	copy(res, names)
+	for _, v := range values {
+		found := false
+		for _, n := range res {
+			if n == "*" || strings.EqualFold(n, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// isFileServerRequest returns true if r is a request made to a file server,
+// see WithFileServer.
+func isFileServerRequest(r *http.Request) bool {
+	ok, _ := r.Context().Value(fileServerKey).(bool)
+	return ok
+}
+
+// isWebSocketUpgrade returns true if r is a WebSocket upgrade request.
+func isWebSocketUpgrade(r *http.Request) bool {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+	for _, v := range r.Header.Values("Connection") {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), "upgrade") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSameOrigin returns true if the host of the given origin is the request
+// host.
+func isSameOrigin(r *http.Request, origin string) bool {
+	i := strings.Index(origin, "://")
+	if i < 0 {
+		return false
+	}
+	return strings.EqualFold(origin[i+3:], r.Host)
+}
+
+// isPrivateNetworkRequest returns true if r is a Private Network Access
+// preflight request.
+func isPrivateNetworkRequest(r *http.Request) bool {
+	return r.Header.Get("Access-Control-Request-Private-Network") == "true"
+}
+
+// Policies returns the policies stored for the given method if method is not
+// empty, the service level policies otherwise. It returns nil if no policies
+// were stored. The service name is ignored.
+func (s *PolicyStore) Policies(service, method string) []Policy {
+	snap, _ := s.snapshot.Load().(*policySnapshot)
+	if snap == nil {
+		return nil
+	}
+	if method != "" {
+		return snap.methods[method]
+	}
+	return snap.policies
+}
+
+// Store replaces the service level policies. Calling Store with no policy
+// restores the policies defined in the design. Store returns an error and
+// keeps the current policies if the origin of one of the policies cannot be
+// matched: the origins of the policies without an OriginFunc must not be
+// empty, the origins wrapped with "/" must be valid regular expressions, the
+// wildcard origins with a scheme must have a host and a numeric or "*" port
+// and the wildcard origins without a scheme may contain a single "*".
+func (s *PolicyStore) Store(policies ...Policy) error {
+	if err := validatePolicies(policies...); err != nil {
+		return err
+	}
+	s.update(func(snap *policySnapshot) {
+		snap.policies = copyPolicies(policies)
+	})
+	return nil
+}
+
+// StoreMethod replaces the policies of the given method. Calling StoreMethod
+// with no policy restores the policies defined in the design for the method.
+// StoreMethod returns an error and keeps the current policies if the origin of
+// one of the policies is not valid, see Store.
+func (s *PolicyStore) StoreMethod(method string, policies ...Policy) error {
+	if err := validatePolicies(policies...); err != nil {
+		return fmt.Errorf("method %q: %w", method, err)
+	}
+	s.update(func(snap *policySnapshot) {
+		if len(policies) == 0 {
+			delete(snap.methods, method)
+			return
+		}
+		snap.methods[method] = copyPolicies(policies)
+	})
+	return nil
+}
+
+// validatePolicies returns an error if the origin of one of the given policies
+// cannot be matched, see Store.
+func validatePolicies(policies ...Policy) error {
+	for i, p := range policies {
+		if p.OriginFunc != nil {
+			// Origin is ignored
+			continue
+		}
+		if err := validateOrigin(p.Origin); err != nil {
+			return fmt.Errorf("cors: policy %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateOrigin returns an error if the given origin specification cannot be
+// matched, see Store.
+func validateOrigin(spec string) error {
+	switch {
+	case spec == "":
+		return fmt.Errorf("empty origin")
+	case spec == "*":
+		return nil
+	case isRegexpSpec(spec):
+		if _, err := regexp.Compile(strings.Trim(spec, "/")); err != nil {
+			return fmt.Errorf("invalid origin regular expression %q: %w", spec, err)
+		}
+		return nil
+	case !strings.Contains(spec, "*"):
+		return nil
+	}
+	p, ok := parseOriginPattern(spec)
+	if !ok {
+		if strings.Count(spec, "*") > 1 {
+			return fmt.Errorf("invalid origin %q: origins without scheme may contain a single wildcard", spec)
+		}
+		return nil
+	}
+	if len(p.labels) == 1 && p.labels[0] == "" {
+		return fmt.Errorf("invalid origin %q: missing host", spec)
+	}
+	if p.port != "" && p.port != "*" {
+		if _, err := strconv.ParseUint(p.port, 10, 16); err != nil {
+			return fmt.Errorf("invalid origin %q: invalid port %q", spec, p.port)
+		}
+	}
+	return nil
+}
+
+// update applies fn to a copy of the current snapshot and stores the result.
+func (s *PolicyStore) update(fn func(*policySnapshot)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := &policySnapshot{methods: make(map[string][]Policy)}
+	if cur, _ := s.snapshot.Load().(*policySnapshot); cur != nil {
+		next.policies = cur.policies
+		for m, ps := range cur.methods {
+			next.methods[m] = ps
+		}
+	}
+	fn(next)
+	s.snapshot.Store(next)
+}
+
+// copyPolicies returns a deep copy of policies so that the caller may not
+// modify the stored policies, it returns nil if policies is empty.
+func copyPolicies(policies []Policy) []Policy {
+	if len(policies) == 0 {
+		return nil
+	}
+	res := make([]Policy, len(policies))
+	for i, p := range policies {
+		p.Methods = copyNames(p.Methods)
+		p.Exposed = copyNames(p.Exposed)
+		p.Headers = copyNames(p.Headers)
+		res[i] = p
+	}
+	return res
+}
+
+// copyNames returns a copy of names or nil if names is nil.
+func copyNames(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(names)), names...)
+}
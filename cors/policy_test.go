@@ -0,0 +1,616 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	policies := []Policy{
+		{Origin: "http://strict.goa.design", Methods: []string{"GET", "PUT"}, Headers: []string{"X-Shared-Secret"}, Strict: true},
+		{Origin: "/.*regexp[.]goa[.]design/", Exposed: []string{"X-Regexp"}},
+		{Origin: "*.goa.design", Methods: []string{"GET"}, Exposed: []string{"X-Time"}, MaxAge: 600, Credentials: true},
+	}
+	cases := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+		origin  string
+		methods string
+		exposed string
+		maxAge  string
+		creds   string
+	}{
+		{"regexp", "GET", map[string]string{"Origin": "http://regexp.goa.design"}, http.StatusOK, "http://regexp.goa.design", "", "X-Regexp", "", ""},
+		{"no-origin", "GET", nil, http.StatusOK, "", "", "", "", ""},
+		{"no-match", "GET", map[string]string{"Origin": "http://other.design"}, http.StatusOK, "", "", "", "", ""},
+		{"match", "GET", map[string]string{"Origin": "http://swagger.goa.design"}, http.StatusOK, "http://swagger.goa.design", "", "X-Time", "600", "true"},
+		{"preflight", "OPTIONS", map[string]string{"Origin": "http://swagger.goa.design", "Access-Control-Request-Method": "GET"}, http.StatusOK, "http://swagger.goa.design", "GET", "X-Time", "600", "true"},
+		{"strict-preflight", "OPTIONS", map[string]string{"Origin": "http://strict.goa.design", "Access-Control-Request-Method": "PUT", "Access-Control-Request-Headers": "x-shared-secret"}, http.StatusOK, "http://strict.goa.design", "GET, PUT", "", "", ""},
+		{"strict-method", "OPTIONS", map[string]string{"Origin": "http://strict.goa.design", "Access-Control-Request-Method": "DELETE"}, http.StatusForbidden, "", "", "", "", ""},
+		{"strict-headers", "OPTIONS", map[string]string{"Origin": "http://strict.goa.design", "Access-Control-Request-Method": "PUT", "Access-Control-Request-Headers": "x-other"}, http.StatusForbidden, "", "", "", "", ""},
+		{"strict-actual", "DELETE", map[string]string{"Origin": "http://strict.goa.design"}, http.StatusOK, "", "", "", "", ""},
+		{"strict-no-match", "OPTIONS", map[string]string{"Origin": "http://other.design", "Access-Control-Request-Method": "GET"}, http.StatusForbidden, "", "", "", "", ""},
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handlers := map[string]http.Handler{
+		"handler": Handler(policies...)(h),
+		"apply": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Apply(w, r, h, policies)
+		}),
+	}
+	for _, tc := range cases {
+		for n, handler := range handlers {
+			t.Run(n+"/"+tc.name, func(t *testing.T) {
+				r := httptest.NewRequest(tc.method, "/", nil)
+				for k, v := range tc.headers {
+					r.Header.Set(k, v)
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				if w.Code != tc.status {
+					t.Errorf("got status %d, expected %d", w.Code, tc.status)
+				}
+				for k, v := range map[string]string{
+					"Access-Control-Allow-Origin":      tc.origin,
+					"Access-Control-Allow-Methods":     tc.methods,
+					"Access-Control-Expose-Headers":    tc.exposed,
+					"Access-Control-Max-Age":           tc.maxAge,
+					"Access-Control-Allow-Credentials": tc.creds,
+				} {
+					if got := w.Header().Get(k); got != v {
+						t.Errorf("got %s %q, expected %q", k, got, v)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestPolicyStore(t *testing.T) {
+	var s PolicyStore
+	if ps := s.Policies("svc", ""); ps != nil {
+		t.Errorf("got %v, expected nil policies for zero value", ps)
+	}
+	if err := s.Store(Policy{Origin: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StoreMethod("m", Policy{Origin: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if ps := s.Policies("svc", ""); len(ps) != 1 || ps[0].Origin != "a" {
+		t.Errorf("got %v, expected service policy a", ps)
+	}
+	if ps := s.Policies("svc", "m"); len(ps) != 1 || ps[0].Origin != "b" {
+		t.Errorf("got %v, expected method policy b", ps)
+	}
+	if ps := s.Policies("svc", "other"); ps != nil {
+		t.Errorf("got %v, expected nil policies for method without policies", ps)
+	}
+	if err := s.StoreMethod("m"); err != nil {
+		t.Fatal(err)
+	}
+	if ps := s.Policies("svc", "m"); ps != nil {
+		t.Errorf("got %v, expected nil policies after reset", ps)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := s.Store(Policy{Origin: "c"}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			s.Policies("svc", "")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPolicyStoreInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		origin string
+	}{
+		{"empty", ""},
+		{"regexp", "/goa[.design/"},
+		{"no-host", "https://:*"},
+		{"port", "https://*.goa.design:http"},
+		{"wildcards", "*.goa.*"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var s PolicyStore
+			if err := s.Store(Policy{Origin: "a"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.StoreMethod("m", Policy{Origin: "b"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Store(Policy{Origin: "c"}, Policy{Origin: c.origin}); err == nil {
+				t.Errorf("Store: got no error for origin %q", c.origin)
+			}
+			if err := s.StoreMethod("m", Policy{Origin: c.origin}); err == nil {
+				t.Errorf("StoreMethod: got no error for origin %q", c.origin)
+			}
+			if ps := s.Policies("svc", ""); len(ps) != 1 || ps[0].Origin != "a" {
+				t.Errorf("got %v, expected previous service policy a", ps)
+			}
+			if ps := s.Policies("svc", "m"); len(ps) != 1 || ps[0].Origin != "b" {
+				t.Errorf("got %v, expected previous method policy b", ps)
+			}
+		})
+	}
+	var s PolicyStore
+	if err := s.Store(Policy{OriginFunc: func(*http.Request) bool { return true }}); err != nil {
+		t.Errorf("got error %q for policy with origin function", err)
+	}
+}
+
+func TestPolicyStoreCopy(t *testing.T) {
+	var s PolicyStore
+	methods := []string{"GET"}
+	headers := []string{"X-Foo"}
+	exposed := []string{"X-Bar"}
+	if err := s.Store(Policy{Origin: "*", Methods: methods, Headers: headers, Exposed: exposed}); err != nil {
+		t.Fatal(err)
+	}
+	methods[0], headers[0], exposed[0] = "DELETE", "X-Baz", "X-Qux"
+	p := s.Policies("svc", "")[0]
+	if p.Methods[0] != "GET" || p.Headers[0] != "X-Foo" || p.Exposed[0] != "X-Bar" {
+		t.Errorf("got %v, expected stored policy not to change with the caller slices", p)
+	}
+}
+
+func TestOriginHandler(t *testing.T) {
+	var store PolicyStore
+	design := []Policy{{Origin: "http://design.goa.design", MaxAge: 1}}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	service := OriginHandler("svc", "", design, WithPolicyProvider(&store))
+	method := OriginHandler("svc", "m", []Policy{{Origin: "http://method.goa.design", MaxAge: 2}}, WithPolicyProvider(&store))
+	inherit := func(h http.Handler, opts ...Option) http.Handler { return OriginHandler("svc", "", design, opts...)(h) }
+	handlers := map[string]http.Handler{
+		"service":  service(h),
+		"method":   method(h),
+		"inherits": MethodHandler("svc", "other", inherit, WithPolicyProvider(&store))(h),
+	}
+	cases := []struct {
+		name     string
+		store    func() error
+		expected map[string]string
+	}{
+		{"design", func() error { return nil }, map[string]string{"service": "1", "method": "2", "inherits": "1"}},
+		{"service", func() error { return store.Store(Policy{Origin: "*", MaxAge: 3}) }, map[string]string{"service": "3", "method": "2", "inherits": "3"}},
+		{"methods", func() error {
+			if err := store.StoreMethod("m", Policy{Origin: "*", MaxAge: 4}); err != nil {
+				return err
+			}
+			return store.StoreMethod("other", Policy{Origin: "*", MaxAge: 5})
+		}, map[string]string{"service": "3", "method": "4", "inherits": "5"}},
+	}
+	origins := map[string]string{"service": "http://design.goa.design", "method": "http://method.goa.design", "inherits": "http://design.goa.design"}
+	for _, tc := range cases {
+		if err := tc.store(); err != nil {
+			t.Fatal(err)
+		}
+		for n, handler := range handlers {
+			t.Run(tc.name+"/"+n, func(t *testing.T) {
+				r := httptest.NewRequest("GET", "/", nil)
+				r.Header.Set("Origin", origins[n])
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				if got := w.Header().Get("Access-Control-Max-Age"); got != tc.expected[n] {
+					t.Errorf("got Access-Control-Max-Age %q, expected %q", got, tc.expected[n])
+				}
+			})
+		}
+	}
+}
+
+func TestApplyCache(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for i, origin := range []string{"https://a.goa.design", "https://b.goa.design"} {
+		// New slices and origin functions on each call
+		tenant := origin
+		policies := []Policy{
+			{Origin: "/^https://app[.]goa[.]design$/", MaxAge: 1},
+			{OriginFunc: func(r *http.Request) bool { return r.Header.Get("Origin") == tenant }, MaxAge: 2},
+		}
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		Apply(w, r, h, policies)
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Errorf("%d: got Access-Control-Allow-Origin %q, expected %q", i, got, origin)
+		}
+	}
+	applyCache.RLock()
+	defer applyCache.RUnlock()
+	if _, ok := applyCache.sets[policiesKey([]Policy{{Origin: "/^https://app[.]goa[.]design$/", MaxAge: 1}, {OriginFunc: func(*http.Request) bool { return false }, MaxAge: 2}})]; !ok {
+		t.Errorf("policies not cached by contents")
+	}
+}
+
+func TestHandlerPrivateNetwork(t *testing.T) {
+	policies := []Policy{
+		{Origin: "http://private.goa.design", PrivateNetwork: true},
+		{Origin: "http://strict.goa.design", Strict: true},
+		{Origin: "*"},
+	}
+	cases := []struct {
+		name   string
+		origin string
+		status int
+		header string
+	}{
+		{"allowed", "http://private.goa.design", http.StatusOK, "true"},
+		{"not-allowed", "http://public.goa.design", http.StatusOK, ""},
+		{"strict", "http://strict.goa.design", http.StatusForbidden, ""},
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := Handler(policies...)(h)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("OPTIONS", "/", nil)
+			r.Header.Set("Origin", tc.origin)
+			r.Header.Set("Access-Control-Request-Method", "GET")
+			r.Header.Set("Access-Control-Request-Private-Network", "true")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Private-Network"); got != tc.header {
+				t.Errorf("got Access-Control-Allow-Private-Network %q, expected %q", got, tc.header)
+			}
+		})
+	}
+}
+
+func TestHandlerVary(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := Handler(Policy{Origin: "http://goa.design"})(h)
+	cases := []struct {
+		name     string
+		method   string
+		origin   string
+		expected string
+	}{
+		{"no-origin", "GET", "", "Accept-Encoding, Origin"},
+		{"match", "GET", "http://goa.design", "Accept-Encoding, Origin"},
+		{"no-match", "GET", "http://other.design", "Accept-Encoding, Origin"},
+		{"preflight", "OPTIONS", "http://goa.design", "Accept-Encoding, Origin, Access-Control-Request-Method, Access-Control-Request-Headers"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.method == "OPTIONS" {
+				r.Header.Set("Access-Control-Request-Method", "GET")
+			}
+			w := httptest.NewRecorder()
+			w.Header().Set("Vary", "Accept-Encoding")
+			handler.ServeHTTP(w, r)
+			if got := strings.Join(w.Header().Values("Vary"), ", "); got != tc.expected {
+				t.Errorf("got Vary %q, expected %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestHandlerWebSocket(t *testing.T) {
+	policies := []Policy{{Origin: "http://goa.design"}}
+	cases := []struct {
+		name        string
+		origin      string
+		status      int
+		checkOrigin bool
+	}{
+		{"allowed", "http://goa.design", http.StatusSwitchingProtocols, true},
+		{"same-origin", "http://example.com", http.StatusSwitchingProtocols, true},
+		{"no-origin", "", http.StatusSwitchingProtocols, true},
+		{"not-allowed", "http://other.goa.design", http.StatusForbidden, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var checked bool
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				checked = CheckWebSocketOrigin(r)
+				w.WriteHeader(http.StatusSwitchingProtocols)
+			})
+			r := httptest.NewRequest("GET", "http://example.com/ws", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			r.Header.Set("Connection", "keep-alive, Upgrade")
+			r.Header.Set("Upgrade", "websocket")
+			w := httptest.NewRecorder()
+			Handler(policies...)(h).ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+			if checked != tc.checkOrigin {
+				t.Errorf("got CheckWebSocketOrigin %v, expected %v", checked, tc.checkOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+				t.Errorf("got Access-Control-Allow-Origin %q, expected none", got)
+			}
+		})
+	}
+}
+
+func TestHandlerGRPCWeb(t *testing.T) {
+	policies := []Policy{{Origin: "http://goa.design", Methods: []string{"GET"}, Headers: []string{"X-Shared-Secret"}, GRPCWeb: true, Strict: true}}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	r := httptest.NewRequest("OPTIONS", "/calc.Calc/Add", nil)
+	r.Header.Set("Origin", "http://goa.design")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+	r.Header.Set("Access-Control-Request-Headers", "content-type, x-grpc-web, x-user-agent")
+	w := httptest.NewRecorder()
+	Handler(policies...)(h).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("got Access-Control-Allow-Methods %q, expected %q", got, "GET, POST")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Headers"); got != "X-Shared-Secret, Content-Type, X-Grpc-Web, X-User-Agent, Grpc-Timeout" {
+		t.Errorf("got Access-Control-Allow-Headers %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "Grpc-Status, Grpc-Message, Grpc-Status-Details-Bin" {
+		t.Errorf("got Access-Control-Expose-Headers %q", got)
+	}
+	if len(policies[0].Methods) != 1 || len(policies[0].Headers) != 1 || len(policies[0].Exposed) != 0 {
+		t.Errorf("policy was modified: %+v", policies[0])
+	}
+}
+
+func TestHandlerGRPCWebPreflight(t *testing.T) {
+	// Same as the handler generated for testdata.GRPCWebOriginDSL, see
+	// testdata.GRPCWebOriginHandleCode.
+	handleOrigin := func(h http.Handler, opts ...Option) http.Handler {
+		return OriginHandler("GRPCWebOrigin", "", []Policy{
+			{
+				Origin:  "http://goa.design",
+				Methods: []string{"POST"},
+				GRPCWeb: true,
+			},
+		}, opts...)(EndPreflight(h))
+	}
+	cases := []struct {
+		name   string
+		method string
+		origin string
+		acrm   string
+		called bool
+	}{
+		{"preflight", "OPTIONS", "http://goa.design", "POST", false},
+		{"unauthorized-preflight", "OPTIONS", "http://evil.com", "POST", true},
+		{"options", "OPTIONS", "http://goa.design", "", true},
+		{"actual", "POST", "http://goa.design", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var called bool
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+			})
+			r := httptest.NewRequest(c.method, "/calc.Calc/Add", nil)
+			r.Header.Set("Origin", c.origin)
+			if c.acrm != "" {
+				r.Header.Set("Access-Control-Request-Method", c.acrm)
+			}
+			w := httptest.NewRecorder()
+			handleOrigin(h).ServeHTTP(w, r)
+			if called != c.called {
+				t.Errorf("got wrapped handler called %t, expected %t", called, c.called)
+			}
+			if !c.called {
+				if w.Code != http.StatusOK {
+					t.Errorf("got status %d, expected %d", w.Code, http.StatusOK)
+				}
+				if got := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, "X-Grpc-Web") {
+					t.Errorf("got Access-Control-Allow-Headers %q, expected to contain X-Grpc-Web", got)
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerObserver(t *testing.T) {
+	policies := []Policy{{Origin: "http://goa.design", Methods: []string{"GET"}}}
+	cases := []struct {
+		name      string
+		method    string
+		origin    string
+		acrm      string
+		policy    string
+		preflight bool
+		observed  bool
+	}{
+		{"matching", "GET", "http://goa.design", "", "http://goa.design", false, true},
+		{"matching-preflight", "OPTIONS", "http://goa.design", "GET", "http://goa.design", true, true},
+		{"not-matching", "GET", "http://other.goa.design", "", "", false, true},
+		{"not-matching-preflight", "OPTIONS", "http://other.goa.design", "GET", "", true, true},
+		{"no-origin", "GET", "", "", "", false, false},
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				observed  bool
+				origin    string
+				policy    string
+				preflight bool
+			)
+			obs := ObserverFunc(func(r *http.Request, o string, p *Policy, pf bool) {
+				observed, origin, preflight = true, o, pf
+				if p != nil {
+					policy = p.Origin
+				}
+			})
+			handler := OriginHandler("svc", "", policies, WithObserver(obs))(h)
+			r := httptest.NewRequest(tc.method, "/", nil)
+			if tc.origin != "" {
+				r.Header.Set("Origin", tc.origin)
+			}
+			if tc.acrm != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.acrm)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+			if observed != tc.observed {
+				t.Fatalf("got observed %v, expected %v", observed, tc.observed)
+			}
+			if origin != tc.origin {
+				t.Errorf("got origin %q, expected %q", origin, tc.origin)
+			}
+			if policy != tc.policy {
+				t.Errorf("got policy %q, expected %q", policy, tc.policy)
+			}
+			if preflight != tc.preflight {
+				t.Errorf("got preflight %v, expected %v", preflight, tc.preflight)
+			}
+		})
+	}
+}
+
+func TestHandlerOriginFunc(t *testing.T) {
+	tenants := func(r *http.Request) bool { return r.Header.Get("Origin") == "https://tenant.goa.design" }
+	policies := []Policy{
+		{Origin: "https://goa.design", MaxAge: 1},
+		{OriginFunc: tenants, Methods: []string{"GET"}, MaxAge: 2},
+		{Origin: "https://*.goa.design", MaxAge: 3},
+	}
+	cases := []struct {
+		name   string
+		origin string
+		maxAge string
+	}{
+		{"first", "https://goa.design", "1"},
+		{"func", "https://tenant.goa.design", "2"},
+		{"after-func", "https://other.goa.design", "3"},
+		{"none", "https://example.com", ""},
+	}
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := Handler(policies...)(h)
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("OPTIONS", "/", nil)
+			r.Header.Set("Origin", tc.origin)
+			r.Header.Set("Access-Control-Request-Method", "GET")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tc.maxAge {
+				t.Errorf("got Access-Control-Max-Age %q, expected %q", got, tc.maxAge)
+			}
+		})
+	}
+}
+
+func TestHandlerOriginFuncStrict(t *testing.T) {
+	tenants := func(r *http.Request) bool { return r.Header.Get("Origin") == "https://tenant.goa.design" }
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := Handler(Policy{OriginFunc: tenants, Methods: []string{"GET"}, Strict: true})(h)
+	cases := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{"func", "https://tenant.goa.design", http.StatusOK},
+		{"none", "https://example.com", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("OPTIONS", "/", nil)
+			r.Header.Set("Origin", tc.origin)
+			r.Header.Set("Access-Control-Request-Method", "GET")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+		})
+	}
+}
+
+func TestHandlerFileServer(t *testing.T) {
+	policies := []Policy{{Origin: "http://goa.design", Methods: []string{"GET"}, Exposed: []string{"X-Time"}, MaxAge: 600, Strict: true}}
+	files := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Range", "bytes 0-1/10")
+		w.WriteHeader(http.StatusPartialContent)
+	})
+	handler := Handler(policies...)(files)
+	serve := func(r *http.Request, fileServer bool) *httptest.ResponseRecorder {
+		if fileServer {
+			r = r.WithContext(WithFileServer(r.Context()))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("preflight", func(t *testing.T) {
+		r := httptest.NewRequest("OPTIONS", "/static/file.txt", nil)
+		r.Header.Set("Origin", "http://goa.design")
+		r.Header.Set("Access-Control-Request-Method", "GET")
+		r.Header.Set("Access-Control-Request-Headers", "range, if-none-match")
+		if w := serve(r, false); w.Code != http.StatusForbidden {
+			t.Errorf("got status %d without file server, expected %d", w.Code, http.StatusForbidden)
+		}
+		w := serve(r, true)
+		if w.Code == http.StatusForbidden {
+			t.Fatalf("got status %d", w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Range, If-Range, If-None-Match, If-Modified-Since" {
+			t.Errorf("got Access-Control-Allow-Headers %q", got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "max-age=600" {
+			t.Errorf("got Cache-Control %q, expected %q", got, "max-age=600")
+		}
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"range", "Range", "bytes=0-1", http.StatusPartialContent},
+		{"not-modified", "If-None-Match", `"v1"`, http.StatusNotModified},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/static/file.txt", nil)
+			r.Header.Set("Origin", "http://goa.design")
+			r.Header.Set(tc.header, tc.value)
+			w := serve(r, true)
+			if w.Code != tc.status {
+				t.Errorf("got status %d, expected %d", w.Code, tc.status)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != "http://goa.design" {
+				t.Errorf("got Access-Control-Allow-Origin %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Time, Accept-Ranges, Content-Range, Content-Length, ETag, Last-Modified" {
+				t.Errorf("got Access-Control-Expose-Headers %q", got)
+			}
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("got Vary %q, expected %q", got, "Origin")
+			}
+		})
+	}
+}
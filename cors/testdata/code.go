@@ -2,283 +2,174 @@ package testdata
 
 var SimpleOriginHandleCode = `// HandleSimpleOriginOrigin applies the CORS response headers corresponding to
 // the origin for the service SimpleOrigin.
-func HandleSimpleOriginOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "SimpleOrigin") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleSimpleOriginOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("SimpleOrigin", "", []cors.Policy{
+		{
+			Origin: "SimpleOrigin",
+		},
+	}, opts...)(h)
 }
 `
 
 var RegexpOriginHandleCode = `// HandleRegexpOriginOrigin applies the CORS response headers corresponding to
 // the origin for the service RegexpOrigin.
-func HandleRegexpOriginOrigin(h http.Handler) http.Handler {
-	spec0 := regexp.MustCompile(".*RegexpOrigin.*")
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOriginRegexp(origin, spec0) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleRegexpOriginOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("RegexpOrigin", "", []cors.Policy{
+		{
+			Origin: "/.*RegexpOrigin.*/",
+		},
+	}, opts...)(h)
 }
 `
 
 var MultiOriginHandleCode = `// HandleMultiOriginOrigin applies the CORS response headers corresponding to
 // the origin for the service MultiOrigin.
-func HandleMultiOriginOrigin(h http.Handler) http.Handler {
-	spec0 := regexp.MustCompile(".*MultiOrigin2.*")
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOriginRegexp(origin, spec0) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Time, X-Api-Version")
-			w.Header().Set("Access-Control-Max-Age", "100")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "MultiOrigin1") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			w.Header().Set("Access-Control-Expose-Headers", "X-Time")
-			w.Header().Set("Access-Control-Max-Age", "600")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
-				w.Header().Set("Access-Control-Allow-Headers", "X-Shared-Secret")
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleMultiOriginOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("MultiOrigin", "", []cors.Policy{
+		{
+			Origin:  "/.*MultiOrigin2.*/",
+			Methods: []string{"GET", "POST"},
+			Exposed: []string{"X-Time", "X-Api-Version"},
+			MaxAge:  100,
+		},
+		{
+			Origin:         "MultiOrigin1",
+			Methods:        []string{"GET", "POST"},
+			Exposed:        []string{"X-Time"},
+			Headers:        []string{"X-Shared-Secret"},
+			MaxAge:         600,
+			Credentials:    true,
+			PrivateNetwork: true,
+		},
+	}, opts...)(h)
 }
 `
 
 var OriginFileServerHandleCode = `// HandleOriginFileServerOrigin applies the CORS response headers corresponding
 // to the origin for the service OriginFileServer.
-func HandleOriginFileServerOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "OriginFileServer") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleOriginFileServerOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("OriginFileServer", "", []cors.Policy{
+		{
+			Origin: "OriginFileServer",
+		},
+	}, opts...)(h)
 }
 `
 
 var OriginMultiEndpointHandleCode = `// HandleOriginMultiEndpointOrigin applies the CORS response headers
 // corresponding to the origin for the service OriginMultiEndpoint.
-func HandleOriginMultiEndpointOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "OriginMultiEndpoint") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleOriginMultiEndpointOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("OriginMultiEndpoint", "", []cors.Policy{
+		{
+			Origin: "OriginMultiEndpoint",
+		},
+	}, opts...)(h)
 }
 `
 
 var MultiServiceSameOriginFirstServiceHandleCode = `// HandleFirstServiceOrigin applies the CORS response headers corresponding to
 // the origin for the service FirstService.
-func HandleFirstServiceOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "SimpleOrigin") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleFirstServiceOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("FirstService", "", []cors.Policy{
+		{
+			Origin: "SimpleOrigin",
+		},
+	}, opts...)(h)
 }
 `
+
 var MultiServiceSameOriginSecondServiceHandleCode = `// HandleSecondServiceOrigin applies the CORS response headers corresponding to
 // the origin for the service SecondService.
-func HandleSecondServiceOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "SimpleOrigin") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleSecondServiceOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("SecondService", "", []cors.Policy{
+		{
+			Origin: "SimpleOrigin",
+		},
+	}, opts...)(h)
 }
 `
 
 var FilesHandleCode = `// HandleFilesOrigin applies the CORS response headers corresponding to the
 // origin for the service Files.
-func HandleFilesOrigin(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			// Not a CORS request
-			h.ServeHTTP(w, r)
-			return
-		}
-		if cors.MatchOrigin(origin, "*") {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Vary", "Origin")
-			if acrm := r.Header.Get("Access-Control-Request-Method"); acrm != "" {
-				// We are handling a preflight request
-			}
-			h.ServeHTTP(w, r)
-			return
-		}
-		h.ServeHTTP(w, r)
-		return
-	})
+func HandleFilesOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("Files", "", []cors.Policy{
+		{
+			Origin: "*",
+		},
+	}, opts...)(h)
 }
 `
 
 var SimpleOriginMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service SimpleOrigin.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleSimpleOriginOrigin(h)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service SimpleOrigin. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hSimpleOriginMethod := HandleSimpleOriginSimpleOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hSimpleOriginMethod.ServeHTTP)
 }
 `
 
 var RegexpOriginMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service RegexpOrigin.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleRegexpOriginOrigin(h)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service RegexpOrigin. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hRegexpOriginMethod := HandleRegexpOriginRegexpOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hRegexpOriginMethod.ServeHTTP)
 }
 `
 
 var MultiOriginMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service MultiOrigin.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleMultiOriginOrigin(h)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service MultiOrigin. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hMultiOriginMethod := HandleMultiOriginMultiOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hMultiOriginMethod.ServeHTTP)
 }
 `
 
 var OriginFileServerMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service OriginFileServer.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleOriginFileServerOrigin(h)
-	mux.Handle("OPTIONS", "/file.json", h.ServeHTTP)
+// service OriginFileServer. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hFiles := HandleOriginFileServerFilesOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/file.json", hFiles.ServeHTTP)
 }
 `
 
 var OriginMultiEndpointMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service OriginMultiEndpoint.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleOriginMultiEndpointOrigin(h)
-	mux.Handle("OPTIONS", "/{:id}", h.ServeHTTP)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service OriginMultiEndpoint. The origin handlers are configured with the
+// given options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hOriginMultiEndpointGet := HandleOriginMultiEndpointOriginMultiEndpointGetOrigin(h, opts...)
+	hOriginMultiEndpointPost := HandleOriginMultiEndpointOriginMultiEndpointPostOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/{:id}", hOriginMultiEndpointGet.ServeHTTP)
+	mux.Handle("OPTIONS", "/", hOriginMultiEndpointPost.ServeHTTP)
 }
 `
 
 var MultiServiceSameOriginFirstServiceMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service FirstService.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleFirstServiceOrigin(h)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service FirstService. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hSimpleOriginMethod := HandleFirstServiceSimpleOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hSimpleOriginMethod.ServeHTTP)
 }
 `
+
 var MultiServiceSameOriginSecondServiceMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service SecondService.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleSecondServiceOrigin(h)
-	mux.Handle("OPTIONS", "/", h.ServeHTTP)
+// service SecondService. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hSimpleOriginMethod := HandleSecondServiceSimpleOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hSimpleOriginMethod.ServeHTTP)
 }
 `
 
 var FilesMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
-// service Files.
-func MountCORSHandler(mux goahttp.Muxer, h http.Handler) {
-	h = HandleFilesOrigin(h)
-	mux.Handle("OPTIONS", "/index", h.ServeHTTP)
+// service Files. The origin handlers are configured with the given options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hFiles := HandleFilesFilesOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/index", hFiles.ServeHTTP)
 }
 `
 
@@ -295,6 +186,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -303,6 +195,7 @@ func New(
 		},
 		SimpleOriginMethod: NewSimpleOriginMethodHandler(e.SimpleOriginMethod, mux, decoder, encoder, errhandler, formatter),
 		CORS:               NewCORSHandler(),
+		corsOptions:        corsOpts,
 	}
 }
 `
@@ -320,6 +213,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -328,6 +222,7 @@ func New(
 		},
 		RegexpOriginMethod: NewRegexpOriginMethodHandler(e.RegexpOriginMethod, mux, decoder, encoder, errhandler, formatter),
 		CORS:               NewCORSHandler(),
+		corsOptions:        corsOpts,
 	}
 }
 `
@@ -345,6 +240,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -353,6 +249,7 @@ func New(
 		},
 		MultiOriginMethod: NewMultiOriginMethodHandler(e.MultiOriginMethod, mux, decoder, encoder, errhandler, formatter),
 		CORS:              NewCORSHandler(),
+		corsOptions:       corsOpts,
 	}
 }
 `
@@ -371,6 +268,7 @@ func New(
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
 	fileSystemFileJSON http.FileSystem,
+	corsOpts ...cors.Option,
 ) *Server {
 	if fileSystemFileJSON == nil {
 		fileSystemFileJSON = http.Dir(".")
@@ -380,8 +278,9 @@ func New(
 			{"CORS", "OPTIONS", "/file.json"},
 			{"./file.json", "GET", "/file.json"},
 		},
-		CORS:     NewCORSHandler(),
-		FileJSON: http.FileServer(fileSystemFileJSON),
+		CORS:        NewCORSHandler(),
+		FileJSON:    HandleOriginFileServerFilesOrigin(http.FileServer(fileSystemFileJSON), corsOpts...),
+		corsOptions: corsOpts,
 	}
 }
 `
@@ -399,6 +298,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -412,6 +312,7 @@ func New(
 		OriginMultiEndpointPost:    NewOriginMultiEndpointPostHandler(e.OriginMultiEndpointPost, mux, decoder, encoder, errhandler, formatter),
 		OriginMultiEndpointOptions: NewOriginMultiEndpointOptionsHandler(e.OriginMultiEndpointOptions, mux, decoder, encoder, errhandler, formatter),
 		CORS:                       NewCORSHandler(),
+		corsOptions:                corsOpts,
 	}
 }
 `
@@ -429,6 +330,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -437,6 +339,7 @@ func New(
 		},
 		SimpleOriginMethod: NewSimpleOriginMethodHandler(e.SimpleOriginMethod, mux, decoder, encoder, errhandler, formatter),
 		CORS:               NewCORSHandler(),
+		corsOptions:        corsOpts,
 	}
 }
 `
@@ -454,6 +357,7 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
 ) *Server {
 	return &Server{
 		Mounts: []*MountPoint{
@@ -462,6 +366,7 @@ func New(
 		},
 		SimpleOriginMethod: NewSimpleOriginMethodHandler(e.SimpleOriginMethod, mux, decoder, encoder, errhandler, formatter),
 		CORS:               NewCORSHandler(),
+		corsOptions:        corsOpts,
 	}
 }
 `
@@ -480,6 +385,7 @@ func New(
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
 	fileSystemIndexHTML http.FileSystem,
+	corsOpts ...cors.Option,
 ) *Server {
 	if fileSystemIndexHTML == nil {
 		fileSystemIndexHTML = http.Dir(".")
@@ -489,8 +395,258 @@ func New(
 			{"CORS", "OPTIONS", "/index"},
 			{"index.html", "GET", "/index"},
 		},
-		CORS:      NewCORSHandler(),
-		IndexHTML: http.FileServer(fileSystemIndexHTML),
+		CORS:        NewCORSHandler(),
+		IndexHTML:   HandleFilesFilesOrigin(http.FileServer(fileSystemIndexHTML), corsOpts...),
+		corsOptions: corsOpts,
+	}
+}
+`
+
+var MethodOriginListHandleCode = `// HandleMethodOriginMethodOriginListOrigin applies the CORS response headers
+// corresponding to the origin for the method MethodOriginList of the service
+// MethodOrigin. The method uses the CORS policies of the service unless the
+// policy provider returns policies for the method.
+func HandleMethodOriginMethodOriginListOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.MethodHandler("MethodOrigin", "MethodOriginList", HandleMethodOriginOrigin, opts...)(h)
+}
+`
+
+var MethodOriginDeleteHandleCode = `// HandleMethodOriginMethodOriginDeleteOrigin applies the CORS response headers
+// corresponding to the origin for the method MethodOriginDelete of the service
+// MethodOrigin.
+func HandleMethodOriginMethodOriginDeleteOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("MethodOrigin", "MethodOriginDelete", []cors.Policy{
+		{
+			Origin:  "AdminOrigin",
+			Methods: []string{"DELETE"},
+		},
+	}, opts...)(h)
+}
+`
+
+var MethodOriginResetHandleCode = `// HandleMethodOriginMethodOriginResetOrigin applies the CORS response headers
+// corresponding to the origin for the method MethodOriginReset of the service
+// MethodOrigin.
+func HandleMethodOriginMethodOriginResetOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("MethodOrigin", "MethodOriginReset", []cors.Policy{
+		{
+			Origin: "/.*AdminOrigin.*/",
+		},
+	}, opts...)(h)
+}
+`
+
+var MethodOriginMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
+// service MethodOrigin. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hMethodOriginList := HandleMethodOriginMethodOriginListOrigin(h, opts...)
+	hMethodOriginDelete := HandleMethodOriginMethodOriginDeleteOrigin(h, opts...)
+	hMethodOriginReset := HandleMethodOriginMethodOriginResetOrigin(h, opts...)
+	h = HandleMethodOriginOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Access-Control-Request-Method") {
+		case "GET":
+			hMethodOriginList.ServeHTTP(w, r)
+		case "DELETE":
+			hMethodOriginDelete.ServeHTTP(w, r)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+	mux.Handle("OPTIONS", "/reset", hMethodOriginReset.ServeHTTP)
+}
+`
+
+var StrictOriginHandleCode = `// HandleStrictOriginOrigin applies the CORS response headers corresponding to
+// the origin for the service StrictOrigin.
+func HandleStrictOriginOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("StrictOrigin", "", []cors.Policy{
+		{
+			Origin:  "StrictOrigin",
+			Methods: []string{"GET", "PUT"},
+			Headers: []string{"X-Shared-Secret"},
+			Strict:  true,
+		},
+	}, opts...)(h)
+}
+`
+
+var StrictOriginMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
+// service StrictOrigin. The origin handlers are configured with the given
+// options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hStrictOriginMethod := HandleStrictOriginStrictOriginMethodOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hStrictOriginMethod.ServeHTTP)
+}
+`
+
+var StrictOriginServerInitCode = `// New instantiates HTTP handlers for all the StrictOrigin service endpoints
+// using the provided encoder and decoder. The handlers are mounted on the
+// given mux using the HTTP verb and path defined in the design. errhandler is
+// called whenever a response fails to be encoded. formatter is used to format
+// errors returned by the service methods prior to encoding. Both errhandler
+// and formatter are optional and can be nil.
+func New(
+	e *strictorigin.Endpoints,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(err error) goahttp.Statuser,
+	corsOpts ...cors.Option,
+) *Server {
+	return &Server{
+		Mounts: []*MountPoint{
+			{"StrictOriginMethod", "PUT", "/"},
+			{"CORS", "OPTIONS", "/"},
+		},
+		StrictOriginMethod: NewStrictOriginMethodHandler(e.StrictOriginMethod, mux, decoder, encoder, errhandler, formatter),
+		CORS:               NewCORSHandler(),
+		corsOptions:        corsOpts,
+	}
+}
+`
+
+var GRPCWebOriginHandleCode = `// HandleGRPCWebOriginOrigin applies the CORS response headers corresponding to
+// the origin for the service GRPCWebOrigin. It responds to the authorized
+// preflight requests without calling h.
+func HandleGRPCWebOriginOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("GRPCWebOrigin", "", []cors.Policy{
+		{
+			Origin:  "GRPCWebOrigin",
+			Methods: []string{"POST"},
+			GRPCWeb: true,
+		},
+	}, opts...)(cors.EndPreflight(h))
+}
+`
+
+var GenerateTestsTestCode = `// TestHandleGenerateTestsOrigin tests the CORS headers set by
+// HandleGenerateTestsOrigin for each origin.
+func TestHandleGenerateTestsOrigin(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Method        string
+		Origin        string
+		RequestMethod string
+		AllowOrigin   string
+		Credentials   bool
+		Skip          string
+	}{
+		{"GET *.goa.design", "GET", "https://example.goa.design", "", "https://example.goa.design", false, ""},
+		{"GET .*GenerateTests.*", "GET", "https://GenerateTests", "", "https://GenerateTests", false, ""},
+		{"GET https://*.goa.design:*", "GET", "https://example.goa.design:8080", "", "https://example.goa.design:8080", true, ""},
+		{"preflight GET https://*.goa.design:*", "OPTIONS", "https://example.goa.design:8080", "GET", "https://example.goa.design:8080", true, ""},
+		{"preflight PUT https://*.goa.design:*", "OPTIONS", "https://example.goa.design:8080", "PUT", "https://example.goa.design:8080", true, ""},
+		{"GET non-matching origin", "GET", "https://cors-test.invalid", "", "", false, ""},
+	}
+	h := HandleGenerateTestsOrigin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if c.Skip != "" {
+				t.Skip(c.Skip)
+			}
+			r := httptest.NewRequest(c.Method, "/", nil)
+			r.Header.Set("Origin", c.Origin)
+			if c.RequestMethod != "" {
+				r.Header.Set("Access-Control-Request-Method", c.RequestMethod)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Errorf("got status %d, expected %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != c.AllowOrigin {
+				t.Errorf("got Access-Control-Allow-Origin %q, expected %q", got, c.AllowOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials") == "true"; got != c.Credentials {
+				t.Errorf("got Access-Control-Allow-Credentials %v, expected %v", got, c.Credentials)
+			}
+			if c.RequestMethod != "" {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, c.RequestMethod) {
+					t.Errorf("got Access-Control-Allow-Methods %q, expected to contain %q", got, c.RequestMethod)
+				}
+			}
+		})
+	}
+}
+`
+
+var OriginFuncHandleCode = `// HandleOriginFuncOrigin applies the CORS response headers corresponding to
+// the origin for the service OriginFunc.
+func HandleOriginFuncOrigin(h http.Handler, opts ...cors.Option) http.Handler {
+	return cors.OriginHandler("OriginFunc", "", []cors.Policy{
+		{
+			OriginFunc: goahttp2.AllowOrigin,
+		},
+		{
+			OriginFunc: tenants.IsAllowedOrigin,
+			Methods:    []string{"GET"},
+			MaxAge:     600,
+		},
+		{
+			OriginFunc: tenants2.IsAllowedOrigin,
+		},
+		{
+			OriginFunc: allowV1.Origin,
+		},
+	}, opts...)(h)
+}
+`
+
+var DirFilesMountCode = `// MountCORSHandler configures the mux to serve the CORS endpoints for the
+// service DirFiles. The origin handlers are configured with the given options.
+func MountCORSHandler(mux goahttp.Muxer, h http.Handler, opts ...cors.Option) {
+	hDirFilesMethod := HandleDirFilesDirFilesMethodOrigin(h, opts...)
+	hFiles := HandleDirFilesFilesOrigin(h, opts...)
+	mux.Handle("OPTIONS", "/", hDirFilesMethod.ServeHTTP)
+	mux.Handle("OPTIONS", "/static/", hFiles.ServeHTTP)
+	mux.Handle("OPTIONS", "/static/{*filepath}", hFiles.ServeHTTP)
+	mux.Handle("OPTIONS", "/favicon.ico", hFiles.ServeHTTP)
+}
+`
+
+var DirFilesServerInitCode = `// New instantiates HTTP handlers for all the DirFiles service endpoints using
+// the provided encoder and decoder. The handlers are mounted on the given mux
+// using the HTTP verb and path defined in the design. errhandler is called
+// whenever a response fails to be encoded. formatter is used to format errors
+// returned by the service methods prior to encoding. Both errhandler and
+// formatter are optional and can be nil.
+func New(
+	e *dirfiles.Endpoints,
+	mux goahttp.Muxer,
+	decoder func(*http.Request) goahttp.Decoder,
+	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
+	errhandler func(context.Context, http.ResponseWriter, error),
+	formatter func(err error) goahttp.Statuser,
+	fileSystemPublic http.FileSystem,
+	fileSystemFaviconIco http.FileSystem,
+	corsOpts ...cors.Option,
+) *Server {
+	if fileSystemPublic == nil {
+		fileSystemPublic = http.Dir(".")
+	}
+	if fileSystemFaviconIco == nil {
+		fileSystemFaviconIco = http.Dir(".")
+	}
+	return &Server{
+		Mounts: []*MountPoint{
+			{"DirFilesMethod", "GET", "/"},
+			{"CORS", "OPTIONS", "/"},
+			{"CORS", "OPTIONS", "/static/"},
+			{"CORS", "OPTIONS", "/static/{*filepath}"},
+			{"CORS", "OPTIONS", "/favicon.ico"},
+			{"public", "GET", "/static"},
+			{"favicon.ico", "GET", "/favicon.ico"},
+		},
+		DirFilesMethod: NewDirFilesMethodHandler(e.DirFilesMethod, mux, decoder, encoder, errhandler, formatter),
+		CORS:           NewCORSHandler(),
+		Public:         HandleDirFilesFilesOrigin(http.FileServer(fileSystemPublic), corsOpts...),
+		FaviconIco:     HandleDirFilesFilesOrigin(http.FileServer(fileSystemFaviconIco), corsOpts...),
+		corsOptions:    corsOpts,
 	}
 }
 `
@@ -35,6 +35,7 @@ var MultiOriginDSL = func() {
 			cors.Expose("X-Time")
 			cors.MaxAge(600)
 			cors.Credentials()
+			cors.AllowPrivateNetwork()
 		})
 		cors.Origin("/.*MultiOrigin2.*/", func() {
 			cors.Methods("GET", "POST")
@@ -102,3 +103,137 @@ var FilesDSL = func() {
 		Files("/index", "index.html")
 	})
 }
+
+var MethodOriginDSL = func() {
+	Service("MethodOrigin", func() {
+		cors.Origin("*")
+		Method("MethodOriginList", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+		Method("MethodOriginDelete", func() {
+			cors.Origin("AdminOrigin", func() {
+				cors.Methods("DELETE")
+			})
+			HTTP(func() {
+				DELETE("/")
+			})
+		})
+		Method("MethodOriginReset", func() {
+			HTTP(func() {
+				cors.Origin("/.*AdminOrigin.*/")
+				POST("/reset")
+			})
+		})
+	})
+}
+
+var StrictOriginDSL = func() {
+	Service("StrictOrigin", func() {
+		cors.Origin("StrictOrigin", func() {
+			cors.Methods("GET", "PUT")
+			cors.Headers("X-Shared-Secret")
+			cors.Strict()
+		})
+		Method("StrictOriginMethod", func() {
+			HTTP(func() {
+				PUT("/")
+			})
+		})
+	})
+}
+
+var GRPCWebOriginDSL = func() {
+	Service("GRPCWebOrigin", func() {
+		cors.Origin("GRPCWebOrigin", func() {
+			cors.Methods("POST")
+			cors.GRPCWeb()
+		})
+		Method("GRPCWebOriginMethod", func() {
+			GRPC(func() {})
+		})
+	})
+}
+
+var OpenAPIExtensionDSL = func() {
+	API("OpenAPIExtension", func() {
+		cors.Origin("https://api.goa.design")
+	})
+	Service("OpenAPIExtension", func() {
+		cors.Origin("/.*goa.design/", func() {
+			cors.Methods("GET")
+			cors.MaxAge(600)
+		})
+		Method("OpenAPIExtensionList", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+		Method("OpenAPIExtensionDelete", func() {
+			cors.Origin("https://admin.goa.design", func() {
+				cors.Methods("DELETE")
+				cors.Credentials()
+			})
+			HTTP(func() {
+				DELETE("/")
+			})
+		})
+		Method("OpenAPIExtensionOptions", func() {
+			HTTP(func() {
+				OPTIONS("/options")
+			})
+		})
+	})
+}
+
+var GenerateTestsDSL = func() {
+	Service("GenerateTests", func() {
+		cors.GenerateTests()
+		cors.Origin("https://*.goa.design:*", func() {
+			cors.Methods("GET", "PUT")
+			cors.Credentials()
+		})
+		cors.Origin("*.goa.design")
+		cors.Origin("/.*GenerateTests.*/")
+		Method("GenerateTestsMethod", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var OriginFuncDSL = func() {
+	Service("OriginFunc", func() {
+		cors.OriginFunc("github.com/acme/tenants.IsAllowedOrigin", func() {
+			cors.Methods("GET")
+			cors.MaxAge(600)
+		})
+		cors.OriginFunc("gopkg.in/acme/allow.v1.Origin")
+		cors.OriginFunc("github.com/acme/goahttp.AllowOrigin")
+		cors.OriginFunc("github.com/other/tenants.IsAllowedOrigin")
+		Method("OriginFuncMethod", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var DirFilesDSL = func() {
+	Service("DirFiles", func() {
+		cors.Origin("DirFilesOrigin", func() {
+			cors.Methods("GET")
+			cors.MaxAge(600)
+			cors.Strict()
+		})
+		Files("/static/{*filepath}", "public")
+		Files("/favicon.ico", "favicon.ico")
+		Method("DirFilesMethod", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
@@ -7,6 +7,8 @@ require (
 	github.com/gorilla/websocket v1.5.0
 	go.uber.org/zap v1.23.0
 	goa.design/goa/v3 v3.8.4
+	golang.org/x/text v0.3.7
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -27,7 +29,5 @@ require (
 	go.uber.org/multierr v1.8.0 // indirect
 	golang.org/x/mod v0.6.0-dev.0.20220419223038-86c51ed26bb4 // indirect
 	golang.org/x/sys v0.0.0-20220803195053-6e608f9ce704 // indirect
-	golang.org/x/text v0.3.7 // indirect
 	golang.org/x/tools v0.1.12 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
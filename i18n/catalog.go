@@ -0,0 +1,164 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+	"goa.design/plugins/v3/internal/vary"
+	"golang.org/x/text/language"
+)
+
+type (
+	// Catalog lists the translated error messages used by the generated
+	// servers. The plugin generates the catalog of the design in the gen/i18n
+	// package.
+	Catalog struct {
+		// Locales lists the locales of the catalog, the first locale is
+		// the default locale.
+		Locales []string
+		// Messages lists the error messages indexed by locale and error
+		// name. The "{field}" placeholder is replaced with the name of the
+		// field that caused the error.
+		Messages map[string]map[string]string
+
+		// once guards the initialization of matcher.
+		once sync.Once
+		// matcher matches the language tags of the locales.
+		matcher language.Matcher
+	}
+
+	// ErrorResponse is the error response returned by the error formatter of
+	// a catalog. The message is translated in the default locale, the
+	// encoder returned by ResponseEncoder translates it in the locale of the
+	// request.
+	ErrorResponse struct {
+		*goahttp.ErrorResponse
+		catalog *Catalog
+		err     *goa.ServiceError
+	}
+
+	// contextKey is the type of the context keys used by the package.
+	contextKey int
+)
+
+// localeKey is the context key used to store the locale of the request.
+const localeKey contextKey = iota + 1
+
+// WithLocale returns a copy of ctx with the given locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// Locale returns the locale stored in ctx or an empty string if there is none.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey).(string)
+	return locale
+}
+
+// Negotiate returns the locale of the catalog best matching the given
+// Accept-Language header value. The header and the locales are matched as
+// language tags (the locale "nl_BE" is the tag "nl-BE") using the quality
+// values and the language matching rules of golang.org/x/text/language.
+// Negotiate returns the default locale if no locale matches and an empty
+// string if the catalog has no locale.
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	if len(c.Locales) == 0 {
+		return ""
+	}
+	desired, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(desired) == 0 {
+		return c.Locales[0]
+	}
+	c.once.Do(func() {
+		tags := make([]language.Tag, len(c.Locales))
+		for i, l := range c.Locales {
+			tags[i] = language.Make(tagOf(l))
+		}
+		c.matcher = language.NewMatcher(tags)
+	})
+	_, i, conf := c.matcher.Match(desired...)
+	if conf == language.No {
+		return c.Locales[0]
+	}
+	return c.Locales[i]
+}
+
+// Message returns the message of the errors with the given name in the given
+// locale or in the default locale if the message is not translated in locale.
+func (c *Catalog) Message(locale, name string) (string, bool) {
+	if msg, ok := c.Messages[locale][name]; ok {
+		return msg, true
+	}
+	if len(c.Locales) > 0 {
+		if msg, ok := c.Messages[c.Locales[0]][name]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// Middleware returns a HTTP middleware that stores the locale negotiated from
+// the request Accept-Language header in the request context.
+func (c *Catalog) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vary.Add(w.Header(), "Accept-Language")
+		locale := c.Negotiate(r.Header.Get("Accept-Language"))
+		h.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), locale)))
+	})
+}
+
+// ErrorFormatter formats the errors returned by the services. It can be given
+// as formatter to the generated HTTP servers. The messages of the errors whose
+// name is listed in the catalog are replaced with the translated message.
+func (c *Catalog) ErrorFormatter(err error) goahttp.Statuser {
+	resp := goahttp.NewErrorResponse(err)
+	gerr, ok := err.(*goa.ServiceError)
+	if !ok {
+		return resp
+	}
+	r := &ErrorResponse{ErrorResponse: resp.(*goahttp.ErrorResponse), catalog: c, err: gerr}
+	if len(c.Locales) > 0 {
+		r.ErrorResponse = r.Localize(c.Locales[0])
+	}
+	return r
+}
+
+// ResponseEncoder wraps the given response encoder so that the error responses
+// returned by the catalog error formatter are translated in the locale stored
+// in the request context by Middleware.
+func (c *Catalog) ResponseEncoder(encoder func(context.Context, http.ResponseWriter) goahttp.Encoder) func(context.Context, http.ResponseWriter) goahttp.Encoder {
+	return func(ctx context.Context, w http.ResponseWriter) goahttp.Encoder {
+		enc := encoder(ctx, w)
+		return goahttp.EncodingFunc(func(v interface{}) error {
+			if r, ok := v.(*ErrorResponse); ok {
+				if locale := Locale(ctx); locale != "" {
+					return enc.Encode(r.Localize(locale))
+				}
+			}
+			return enc.Encode(v)
+		})
+	}
+}
+
+// Localize returns the error response with the message translated in the
+// given locale. The messages of merged errors are translated individually.
+func (r *ErrorResponse) Localize(locale string) *goahttp.ErrorResponse {
+	resp := *r.ErrorResponse
+	history := r.err.History()
+	msgs := make([]string, len(history))
+	for i, e := range history {
+		msgs[i] = e.Message
+		if msg, ok := r.catalog.Message(locale, e.Name); ok {
+			if e.Field != nil {
+				msg = strings.ReplaceAll(msg, "{field}", *e.Field)
+			}
+			msgs[i] = msg
+		}
+	}
+	resp.Message = strings.Join(msgs, "; ")
+	return &resp
+}
@@ -0,0 +1,124 @@
+package i18n_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goahttp "goa.design/goa/v3/http"
+	goa "goa.design/goa/v3/pkg"
+	"goa.design/plugins/v3/i18n"
+)
+
+var catalog = &i18n.Catalog{
+	Locales: []string{"en", "nl_BE", "de"},
+	Messages: map[string]map[string]string{
+		"en": {
+			goa.MissingField: "{field} is required",
+			"not_found":      "Not found",
+		},
+		"nl_BE": {
+			goa.MissingField: "{field} is verplicht",
+		},
+	},
+}
+
+func TestCatalogNegotiate(t *testing.T) {
+	cases := []struct {
+		Name           string
+		AcceptLanguage string
+		Expected       string
+	}{
+		{"empty", "", "en"},
+		{"exact", "de", "de"},
+		{"separator", "nl-be", "nl_BE"},
+		{"language", "nl", "nl_BE"},
+		{"quality", "de;q=0.5, nl-BE;q=0.8", "nl_BE"},
+		{"no-match", "fr", "en"},
+		{"excluded", "de;q=0, fr", "en"},
+		{"wildcard", "fr, *", "en"},
+		{"region", "de-CH", "de"},
+		{"invalid", "not a header;q=x", "en"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if got := catalog.Negotiate(c.AcceptLanguage); got != c.Expected {
+				t.Errorf("got locale %q, expected %q", got, c.Expected)
+			}
+		})
+	}
+}
+
+func TestCatalogErrorFormatter(t *testing.T) {
+	missing := goa.MergeErrors(goa.MissingFieldError("a", "body"), goa.MissingFieldError("b", "body"))
+	cases := []struct {
+		Name            string
+		AcceptLanguage  string
+		Err             error
+		ExpectedMessage string
+	}{
+		{"translated", "nl", missing, "a is verplicht; b is verplicht"},
+		{"default-locale", "de", missing, "a is required; b is required"},
+		{"design-error", "nl", goa.PermanentError("not_found", "item 42 not found"), "Not found"},
+		{"not-translated", "nl", goa.PermanentError("conflict", "item 42 exists"), "item 42 exists"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			encoder := catalog.ResponseEncoder(goahttp.ResponseEncoder)
+			errEncoder := goahttp.ErrorEncoder(encoder, catalog.ErrorFormatter)
+			h := catalog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := errEncoder(r.Context(), w, c.Err); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Accept-Language", c.AcceptLanguage)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			var resp goahttp.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Message != c.ExpectedMessage {
+				t.Errorf("got message %q, expected %q", resp.Message, c.ExpectedMessage)
+			}
+			if got := w.Header().Get("Vary"); got != "Accept-Language" {
+				t.Errorf("got Vary %q, expected Accept-Language", got)
+			}
+		})
+	}
+}
+
+func TestCatalogMiddlewareVary(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Existing string
+		Expected []string
+	}{
+		{"empty", "", []string{"Accept-Language"}},
+		{"merge", "Origin", []string{"Origin", "Accept-Language"}},
+		{"already-listed", "Origin, accept-language", []string{"Origin, accept-language"}},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			h := catalog.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			w := httptest.NewRecorder()
+			if c.Existing != "" {
+				w.Header().Set("Vary", c.Existing)
+			}
+			h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			got := w.Header().Values("Vary")
+			if len(got) != len(c.Expected) {
+				t.Fatalf("got Vary %v, expected %v", got, c.Expected)
+			}
+			for i, v := range c.Expected {
+				if got[i] != v {
+					t.Errorf("got Vary %v, expected %v", got, c.Expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Package catalogs reads and writes the translation catalogs extracted from
+// the i18n designs. The catalogs use the gettext PO, XLIFF 1.2 or go-i18n JSON
+// formats and list the translations of a locale keyed by a stable ID derived
+// from the path of the translated expression in the design.
+package catalogs
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// FormatPO is the gettext PO format.
+	FormatPO = "po"
+	// FormatXLIFF is the XLIFF 1.2 format.
+	FormatXLIFF = "xliff"
+	// FormatJSON is the go-i18n JSON format.
+	FormatJSON = "json"
+)
+
+// Formats lists the supported formats.
+var Formats = []string{FormatPO, FormatXLIFF, FormatJSON}
+
+type (
+	// File is a translation catalog.
+	File struct {
+		// Locale is the locale of the translations.
+		Locale string
+		// SourceLocale is the locale of the source texts.
+		SourceLocale string
+		// Entries lists the translations.
+		Entries []*Entry
+	}
+
+	// Entry is a translation of a catalog.
+	Entry struct {
+		// ID is the stable ID of the translated text.
+		ID string
+		// Description describes the translated expression.
+		Description string
+		// Source is the text in the source locale.
+		Source string
+		// Target is the translated text, it is empty if the text is not
+		// translated yet.
+		Target string
+	}
+)
+
+// Ext returns the file extension used for the given format.
+func Ext(format string) string {
+	if format == FormatXLIFF {
+		return ".xlf"
+	}
+	return "." + format
+}
+
+// FormatOf returns the format of the catalog file with the given path given
+// its extension.
+func FormatOf(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".po":
+		return FormatPO, nil
+	case ".xlf", ".xliff":
+		return FormatXLIFF, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown catalog format for %q, supported extensions are .po, .xlf, .xliff and .json", path)
+}
+
+// LocaleOf returns the locale of the catalog file with the given path: the last
+// dot separated part of the file name without extension (e.g. "nl" for
+// "active.nl.json").
+func LocaleOf(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Write writes the catalog using the given format.
+func Write(w io.Writer, format string, f *File) error {
+	switch format {
+	case FormatPO:
+		return writePO(w, f)
+	case FormatXLIFF:
+		return writeXLIFF(w, f)
+	case FormatJSON:
+		return writeJSON(w, f)
+	}
+	return fmt.Errorf("unknown catalog format %q", format)
+}
+
+// Read reads a catalog using the given format. The locale of the catalog is
+// empty if the format does not record it (go-i18n JSON).
+func Read(r io.Reader, format string) (*File, error) {
+	switch format {
+	case FormatPO:
+		return readPO(r)
+	case FormatXLIFF:
+		return readXLIFF(r)
+	case FormatJSON:
+		return readJSON(r)
+	}
+	return nil, fmt.Errorf("unknown catalog format %q", format)
+}
@@ -0,0 +1,89 @@
+package catalogs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	f := &File{
+		Locale:       "nl",
+		SourceLocale: "en",
+		Entries: []*Entry{
+			{ID: "api.title", Description: "I18N title of API \"calc\"", Source: "Calc API", Target: "Reken API"},
+			{ID: "service.calc.description", Description: "I18N description\nof service", Source: "Say \"hi\"\n<b>&</b>", Target: ""},
+		},
+	}
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, format, f); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := f
+			if format == FormatJSON {
+				// The JSON format records neither the locales nor the
+				// source texts.
+				expected = &File{}
+				for _, e := range f.Entries {
+					expected.Entries = append(expected.Entries, &Entry{ID: e.ID, Description: e.Description, Target: e.Target})
+				}
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("got %+v, expected %+v", got, expected)
+			}
+		})
+	}
+}
+
+func TestReadPO(t *testing.T) {
+	po := `# Translator comment
+msgid ""
+msgstr ""
+"Language: de\n"
+
+#. I18N description of service "calc"
+msgctxt "service.calc.description"
+msgid "The calc service"
+msgstr ""
+"Der Rechen"
+"dienst"
+
+msgctxt "api.title"
+msgid "Calc"
+msgstr "Rechner"
+`
+	f, err := Read(strings.NewReader(po), FormatPO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &File{
+		Locale: "de",
+		Entries: []*Entry{
+			{ID: "service.calc.description", Description: `I18N description of service "calc"`, Source: "The calc service", Target: "Der Rechendienst"},
+			{ID: "api.title", Source: "Calc", Target: "Rechner"},
+		},
+	}
+	if !reflect.DeepEqual(f, expected) {
+		t.Errorf("got %+v, expected %+v", f, expected)
+	}
+}
+
+func TestLocaleOf(t *testing.T) {
+	cases := map[string]string{
+		"i18n/nl.po":           "nl",
+		"active.de_DE.json":    "de_DE",
+		"/tmp/messages.fr.xlf": "fr",
+	}
+	for path, expected := range cases {
+		if got := LocaleOf(path); got != expected {
+			t.Errorf("got locale %q for %q, expected %q", got, path, expected)
+		}
+	}
+}
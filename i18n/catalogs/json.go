@@ -0,0 +1,51 @@
+package catalogs
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// jsonMessage is a message of the go-i18n JSON format.
+type jsonMessage struct {
+	Description string `json:"description,omitempty"`
+	Other       string `json:"other"`
+}
+
+// writeJSON writes the catalog in the go-i18n JSON format: an object whose
+// keys are the IDs and whose values describe the translated message. The
+// format does not record the source texts nor the locale which is given by
+// the file name.
+func writeJSON(w io.Writer, f *File) error {
+	msgs := make(map[string]*jsonMessage, len(f.Entries))
+	for _, e := range f.Entries {
+		msgs[e.ID] = &jsonMessage{Description: e.Description, Other: e.Target}
+	}
+	b, err := json.MarshalIndent(msgs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// readJSON reads a catalog in the go-i18n JSON format. The messages may be
+// objects or strings.
+func readJSON(r io.Reader) (*File, error) {
+	var msgs map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&msgs); err != nil {
+		return nil, err
+	}
+	f := &File{}
+	for id, raw := range msgs {
+		var msg jsonMessage
+		if err := json.Unmarshal(raw, &msg.Other); err != nil {
+			if err := json.Unmarshal(raw, &msg); err != nil {
+				return nil, err
+			}
+		}
+		f.Entries = append(f.Entries, &Entry{ID: id, Description: msg.Description, Target: msg.Other})
+	}
+	sort.Slice(f.Entries, func(i, j int) bool { return f.Entries[i].ID < f.Entries[j].ID })
+	return f, nil
+}
@@ -0,0 +1,128 @@
+package catalogs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// writePO writes the catalog in the gettext PO format. The ID is the message
+// context (msgctxt) of the entries, the description is an extracted comment.
+func writePO(w io.Writer, f *File) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, `msgid ""`)
+	fmt.Fprintln(bw, `msgstr ""`)
+	fmt.Fprintf(bw, "%s\n", poQuote("Language: "+f.Locale+"\n"))
+	if f.SourceLocale != "" {
+		fmt.Fprintf(bw, "%s\n", poQuote("X-Source-Language: "+f.SourceLocale+"\n"))
+	}
+	fmt.Fprintf(bw, "%s\n", poQuote("Content-Type: text/plain; charset=UTF-8\n"))
+	for _, e := range f.Entries {
+		fmt.Fprintln(bw)
+		if e.Description != "" {
+			for _, line := range strings.Split(e.Description, "\n") {
+				fmt.Fprintf(bw, "#. %s\n", line)
+			}
+		}
+		fmt.Fprintf(bw, "msgctxt %s\n", poQuote(e.ID))
+		fmt.Fprintf(bw, "msgid %s\n", poQuote(e.Source))
+		fmt.Fprintf(bw, "msgstr %s\n", poQuote(e.Target))
+	}
+	return bw.Flush()
+}
+
+// readPO reads a catalog in the gettext PO format.
+func readPO(r io.Reader) (*File, error) {
+	var (
+		f       = &File{}
+		entry   = &Entry{}
+		comment []string
+		field   *string
+		started bool
+		hasStr  bool
+		header  string
+	)
+	flush := func() {
+		if started {
+			if entry.ID == "" && entry.Source == "" {
+				header = entry.Target
+			} else {
+				entry.Description = strings.Join(comment, "\n")
+				f.Entries = append(f.Entries, entry)
+			}
+		}
+		entry, comment, field, started, hasStr = &Entry{}, nil, nil, false, false
+	}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#.") {
+				if hasStr {
+					flush()
+				}
+				comment = append(comment, strings.TrimSpace(line[2:]))
+			}
+			continue
+		}
+		keyword, value := "", line
+		if !strings.HasPrefix(line, `"`) {
+			keyword, value, _ = strings.Cut(line, " ")
+		}
+		s, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		switch keyword {
+		case "":
+			if field == nil {
+				return nil, fmt.Errorf("line %d: unexpected string", n)
+			}
+			*field += s
+			continue
+		case "msgctxt", "msgid":
+			if hasStr {
+				flush()
+			}
+			field = &entry.Source
+			if keyword == "msgctxt" {
+				field = &entry.ID
+			}
+		case "msgstr":
+			field = &entry.Target
+			hasStr = true
+		default:
+			return nil, fmt.Errorf("line %d: unsupported keyword %q", n, keyword)
+		}
+		started = true
+		*field = s
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Language":
+			f.Locale = strings.TrimSpace(value)
+		case "X-Source-Language":
+			f.SourceLocale = strings.TrimSpace(value)
+		}
+	}
+	return f, nil
+}
+
+// poQuote returns the PO string literal of s.
+func poQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,72 @@
+package catalogs
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+type (
+	// xliff is the XLIFF 1.2 document.
+	xliff struct {
+		XMLName xml.Name  `xml:"urn:oasis:names:tc:xliff:document:1.2 xliff"`
+		Version string    `xml:"version,attr"`
+		File    xliffFile `xml:"file"`
+	}
+
+	// xliffFile is the file element of a XLIFF document.
+	xliffFile struct {
+		Original       string      `xml:"original,attr"`
+		SourceLanguage string      `xml:"source-language,attr"`
+		TargetLanguage string      `xml:"target-language,attr,omitempty"`
+		Datatype       string      `xml:"datatype,attr"`
+		Units          []xliffUnit `xml:"body>trans-unit"`
+	}
+
+	// xliffUnit is a translation unit of a XLIFF document.
+	xliffUnit struct {
+		ID     string `xml:"id,attr"`
+		Source string `xml:"source"`
+		Target string `xml:"target"`
+		Note   string `xml:"note,omitempty"`
+	}
+)
+
+// writeXLIFF writes the catalog in the XLIFF 1.2 format. The ID is the ID of
+// the translation units, the description is a note.
+func writeXLIFF(w io.Writer, f *File) error {
+	doc := xliff{
+		Version: "1.2",
+		File: xliffFile{
+			Original:       "design",
+			SourceLanguage: f.SourceLocale,
+			TargetLanguage: f.Locale,
+			Datatype:       "plaintext",
+		},
+	}
+	for _, e := range f.Entries {
+		doc.File.Units = append(doc.File.Units, xliffUnit{ID: e.ID, Source: e.Source, Target: e.Target, Note: e.Description})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// readXLIFF reads a catalog in the XLIFF 1.2 format.
+func readXLIFF(r io.Reader) (*File, error) {
+	var doc xliff
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	f := &File{Locale: doc.File.TargetLanguage, SourceLocale: doc.File.SourceLanguage}
+	for _, u := range doc.File.Units {
+		f.Entries = append(f.Entries, &Entry{ID: u.ID, Description: u.Note, Source: u.Source, Target: u.Target})
+	}
+	return f, nil
+}
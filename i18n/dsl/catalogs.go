@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"goa.design/plugins/v3/i18n/catalogs"
+)
+
+// Catalogs holds the translations loaded from catalog files indexed by locale
+// and ID.
+type Catalogs struct {
+	messages map[string]map[string]string
+}
+
+// LoadCatalogs loads the translations of the catalog files written by Extract
+// and possibly edited by translators. The format of a file is given by its
+// extension (.po, .xlf, .xliff or .json) and its locale by its header or, if
+// the header does not define it, by its name (e.g. nl.po). Relative paths are
+// relative to the directory of the design file calling LoadCatalogs.
+//
+// Example:
+//
+//	var tr = i18n.MustLoadCatalogs("i18n/en.po", "i18n/nl.po")
+//
+//	var _ = Service("calc", func() {
+//	    i18n.Description(tr.T("service.calc.description"))
+//	})
+func LoadCatalogs(paths ...string) (*Catalogs, error) {
+	dir := ""
+	if _, file, _, ok := runtime.Caller(1); ok {
+		dir = filepath.Dir(file)
+	}
+	return loadCatalogs(dir, paths)
+}
+
+// MustLoadCatalogs is like LoadCatalogs but panics if a catalog cannot be
+// loaded.
+func MustLoadCatalogs(paths ...string) *Catalogs {
+	dir := ""
+	if _, file, _, ok := runtime.Caller(1); ok {
+		dir = filepath.Dir(file)
+	}
+	c, err := loadCatalogs(dir, paths)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// T returns the translations of the catalog entries with the given ID. The
+// translation is empty if the entry is missing or not translated in a locale
+// so that it is reported as missing by Report and Strict.
+func (c *Catalogs) T(id string) Translateable {
+	return func(locale string) string {
+		return c.messages[locale][id]
+	}
+}
+
+// loadCatalogs loads the catalog files, relative paths are relative to dir.
+func loadCatalogs(dir string, paths []string) (*Catalogs, error) {
+	c := &Catalogs{messages: make(map[string]map[string]string)}
+	for _, path := range paths {
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		if err := c.load(path); err != nil {
+			return nil, fmt.Errorf("i18n: failed to load catalog %s: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+// load loads the catalog file at path.
+func (c *Catalogs) load(path string) error {
+	format, err := catalogs.FormatOf(path)
+	if err != nil {
+		return err
+	}
+	r, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	f, err := catalogs.Read(r, format)
+	if err != nil {
+		return err
+	}
+	locale := f.Locale
+	if locale == "" {
+		locale = catalogs.LocaleOf(path)
+	}
+	msgs, ok := c.messages[locale]
+	if !ok {
+		msgs = make(map[string]string)
+		c.messages[locale] = msgs
+	}
+	for _, e := range f.Entries {
+		target := e.Target
+		if target == "" && locale == f.SourceLocale {
+			target = e.Source
+		}
+		if target != "" {
+			msgs[e.ID] = target
+		}
+	}
+	return nil
+}
@@ -1,7 +1,12 @@
 package i18n
 
 import (
+	"regexp"
+	"strings"
+
 	"goa.design/goa/v3/eval"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/i18n/catalogs"
 	"goa.design/plugins/v3/i18n/expr"
 
 	// Register code generators for the I18n plugin
@@ -10,31 +15,263 @@ import (
 
 type Translateable = func(locale string) string
 
-func Title(t Translateable) {
-	i18n := &expr.I18nExpr{Trans: []Translateable{t}}
+// Locales sets the locales used to generate the translated specs, the first
+// locale is the default locale. The locales are BCP 47 language tags that may
+// use underscores as separators. The missing translations of a locale fall
+// back to its parent locales and then to the default locale, e.g. de_AT falls
+// back to de. The locales defined in the locale file or in the GOA_I18N
+// environment variable take precedence.
+//
+// Locales must appear in an API expression.
+//
+// Example:
+//
+//	var _ = API("calc", func() {
+//	    i18n.Locales("en", "nl", "de_DE")
+//	})
+func Locales(locales ...string) {
+	if _, ok := eval.Current().(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	expr.Root.Locales = locales
+}
 
-	current := eval.Current()
-	i18n.Parent = current
+// Strict makes the generation fail if a translation is empty or matches the
+// placeholder defined with Placeholder in any locale.
+//
+// Strict must appear in an API expression.
+func Strict() {
+	if _, ok := eval.Current().(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	expr.Root.Strict = true
+}
+
+// Report generates the gen/i18n/report.json file listing for each locale the
+// translations that are missing (empty or matching the placeholder defined
+// with Placeholder) and the translations identical to the default locale.
+//
+// Report must appear in an API expression.
+func Report() {
+	if _, ok := eval.Current().(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	expr.Root.Report = true
+}
 
-	expr.Root.Title[current] = i18n
+// Placeholder sets the regular expression matching the translations returned
+// by the Translateable functions for missing translations. Strict and Report
+// consider these translations missing.
+//
+// Placeholder must appear in an API expression.
+//
+// Example:
+//
+//	var _ = API("calc", func() {
+//	    i18n.Placeholder(`^\*.*\*$`) // M returns "*label*" for missing labels
+//	    i18n.Strict()
+//	})
+func Placeholder(pattern string) {
+	if _, ok := eval.Current().(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		eval.ReportError("invalid placeholder %q: %s", pattern, err)
+		return
+	}
+	expr.Root.Placeholder = re
+}
+
+// Extract writes the translations of each locale to catalog files in the
+// gen/i18n directory (e.g. gen/i18n/nl.po) so that they can be edited by
+// translators. The supported formats are "po" (gettext), "xliff" (XLIFF 1.2)
+// and "json" (go-i18n). The entries are keyed by a stable ID derived from the
+// path of the translated expression in the design, e.g.
+// "service.calc.method.add.description". The catalogs can be loaded back with
+// LoadCatalogs.
+//
+// Extract must appear in an API expression.
+//
+// Example:
+//
+//	var _ = API("calc", func() {
+//	    i18n.Locales("en", "nl")
+//	    i18n.Extract("po", "xliff")
+//	})
+func Extract(formats ...string) {
+	if _, ok := eval.Current().(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	for _, f := range formats {
+		if !isFormat(f) {
+			eval.ReportError("unknown catalog format %q, supported formats are %s", f, strings.Join(catalogs.Formats, ", "))
+			return
+		}
+	}
+	expr.Root.Extract = formats
+}
+
+// ServeLocalized makes the file server serve the files generated for the
+// locale best matching the Accept-Language header of the requests, e.g.
+// gen/http/openapi3_nl.json instead of gen/http/openapi3.json. The generated
+// HTTP server wraps the file server with the LocalizedFiles handler of the
+// plugin package which sets the Content-Language and Vary response headers.
+//
+// ServeLocalized must appear in a Files expression.
+//
+// Example:
+//
+//	var _ = Service("openapi", func() {
+//	    Files("/openapi3.json", "./gen/http/openapi3.json", func() {
+//	        i18n.ServeLocalized()
+//	    })
+//	})
+func ServeLocalized() {
+	fs, ok := eval.Current().(*goaexpr.HTTPFileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	expr.Root.LocalizedFiles = append(expr.Root.LocalizedFiles, fs)
+}
+
+// Title adds a translatable API title.
+//
+// Title must appear in an API expression.
+func Title(t Translateable) {
+	translate(expr.Root.Title, "title", t)
 }
 
-// Description adds a translatable description
+// Description adds a translatable description. Description may appear in any
+// expression supporting the Goa Description DSL: API, Server, Host, Service,
+// Method, Attribute (including payloads, results and errors), Docs, security
+// schemes, HTTP responses and file servers.
 func Description(t Translateable) {
-	i18n := &expr.I18nExpr{Trans: []Translateable{t}}
+	translate(expr.Root.Description, "description", t)
+}
+
+// Example adds a translatable example given its value or its summary and value
+// like the Goa Example DSL.
+//
+// Example must appear in an attribute expression.
+func Example(t ...Translateable) {
+	translate(expr.Root.Example, "example", t...)
+}
 
+// EnumDescription adds a translatable description of an enum value. The
+// descriptions are listed in the order of the enum values by the
+// x-enum-descriptions extension of the attribute schema.
+//
+// EnumDescription must appear in an attribute expression defining the value
+// with Enum.
+//
+// Example:
+//
+//	Attribute("op", String, func() {
+//	    Enum("add", "sub")
+//	    i18n.EnumDescription("add", M("OpAdd"))
+//	    i18n.EnumDescription("sub", M("OpSub"))
+//	})
+func EnumDescription(value interface{}, t Translateable) {
 	current := eval.Current()
-	i18n.Parent = current
+	i18n := &expr.I18nExpr{Name: "enum", Trans: []Translateable{t}, Value: value, Parent: current}
+	expr.Root.Enum[current] = append(expr.Root.Enum[current], i18n)
+	expr.SetKey(current)
+}
 
-	expr.Root.Description[current] = i18n
+// Meta adds a translatable value of the meta with the given key, e.g. the
+// value of a "openapi:extension:x-..." key. The translation replaces the
+// values of the meta in the translated specifications.
+//
+// Meta may appear in any expression supporting the Goa Meta DSL.
+//
+// Example:
+//
+//	Method("add", func() {
+//	    Meta("openapi:summary", "Add numbers")
+//	    i18n.Meta("openapi:summary", M("AddSummary"))
+//	})
+func Meta(name string, t Translateable) {
+	translateMeta("meta", name, t)
 }
 
-// Example adds a translatable description
-func Example(t ...Translateable) {
-	i18n := &expr.I18nExpr{Trans: t}
+// TagDescription adds a translatable description of the OpenAPI tag with the
+// given name, that is the value of the "openapi:tag:<name>:desc" meta (or
+// "swagger:tag:<name>:desc" if the tag is defined with a "swagger:tag:<name>"
+// meta). The tag is defined if the expression does not define it.
+//
+// TagDescription may appear in any expression supporting the Goa Meta DSL, the
+// OpenAPI generators read the tags of the API, HTTP services and HTTP
+// endpoints.
+//
+// Example:
+//
+//	var _ = Service("calc", func() {
+//	    HTTP(func() {
+//	        Meta("openapi:tag:Calc")
+//	        i18n.TagDescription("Calc", M("CalcTag"))
+//	    })
+//	})
+func TagDescription(tag string, t Translateable) {
+	translateMeta("tag", tag, t)
+}
 
+// ErrorMessage adds a translatable message for the errors with the given name.
+// The name may be the name of an error defined in the design or the name of the
+// validation errors produced by Goa (e.g. "missing_field", "invalid_enum_value"
+// or "invalid_range"). The "{field}" placeholder is replaced with the name of the
+// field that caused the error. The messages are listed in the runtime catalog
+// generated in the gen/i18n package.
+//
+// ErrorMessage must appear in an API expression.
+//
+// Example:
+//
+//	var _ = API("calc", func() {
+//	    i18n.ErrorMessage("missing_field", M("MissingField")) // "{field} is required"
+//	})
+func ErrorMessage(name string, t Translateable) {
 	current := eval.Current()
-	i18n.Parent = current
+	if _, ok := current.(*goaexpr.APIExpr); !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	expr.Root.Messages[name] = &expr.I18nExpr{Name: "message", Trans: []Translateable{t}, Value: name, Parent: current}
+}
+
+// translate records the translations of the current expression in m.
+func translate(m map[eval.Expression]*expr.I18nExpr, name string, t ...Translateable) {
+	current := eval.Current()
+	m[current] = &expr.I18nExpr{Name: name, Trans: t, Parent: current}
+	expr.SetKey(current)
+}
+
+// translateMeta records the translation of a meta value of the current
+// expression.
+func translateMeta(name, value string, t Translateable) {
+	current := eval.Current()
+	if expr.MetaOf(current) == nil {
+		if c, ok := current.(goaexpr.CompositeExpr); ok {
+			current = c.Attribute()
+		}
+	}
+	i18n := &expr.I18nExpr{Name: name, Trans: []Translateable{t}, Value: value, Parent: current}
+	expr.Root.Meta[current] = append(expr.Root.Meta[current], i18n)
+	expr.SetKey(current)
+}
 
-	expr.Root.Example[current] = i18n
+// isFormat returns true if f is a supported catalog format.
+func isFormat(f string) bool {
+	for _, format := range catalogs.Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
 }
@@ -14,6 +14,7 @@ import (
 	"goa.design/goa/v3/middleware"
 	calc "goa.design/plugins/v3/i18n/examples/calc/gen/calc"
 	calcsvr "goa.design/plugins/v3/i18n/examples/calc/gen/http/calc/server"
+	"goa.design/plugins/v3/i18n/examples/calc/gen/i18n"
 )
 
 // handleHTTPServer starts configures and starts a HTTP server on the given
@@ -34,7 +35,7 @@ func handleHTTPServer(ctx context.Context, u *url.URL, calcEndpoints *calc.Endpo
 	// see goa.design/implement/encoding.
 	var (
 		dec = goahttp.RequestDecoder
-		enc = goahttp.ResponseEncoder
+		enc = i18n.Catalog.ResponseEncoder(goahttp.ResponseEncoder)
 	)
 
 	// Build the service HTTP request multiplexer and configure it to serve
@@ -53,7 +54,7 @@ func handleHTTPServer(ctx context.Context, u *url.URL, calcEndpoints *calc.Endpo
 	)
 	{
 		eh := errorHandler(logger)
-		calcServer = calcsvr.New(calcEndpoints, mux, dec, enc, eh, nil)
+		calcServer = calcsvr.New(calcEndpoints, mux, dec, enc, eh, i18n.Catalog.ErrorFormatter, nil)
 		if debug {
 			servers := goahttp.Servers{
 				calcServer,
@@ -68,6 +69,7 @@ func handleHTTPServer(ctx context.Context, u *url.URL, calcEndpoints *calc.Endpo
 	// here apply to all the service endpoints.
 	var handler http.Handler = mux
 	{
+		handler = i18n.Catalog.Middleware(handler)
 		handler = httpmdlwr.Log(adapter)(handler)
 		handler = httpmdlwr.RequestID()(handler)
 	}
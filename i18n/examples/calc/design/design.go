@@ -6,8 +6,10 @@ import (
 )
 
 var _ = API("calc", func() {
+	i18n.Locales("en", "nl")
 	i18n.Title(M("ApiCalcTitle"))
 	i18n.Description(M("ApiCalcDescription"))
+	i18n.ErrorMessage("invalid_field_type", M("ApiCalcErrorInvalidFieldType"))
 })
 
 var _ = Service("calc", func() {
@@ -37,4 +39,10 @@ var _ = Service("calc", func() {
 			Response(StatusOK)
 		})
 	})
+
+	// Serve the specification in the locale requested with the
+	// Accept-Language header.
+	Files("/openapi3.json", "./gen/http/openapi3.json", func() {
+		i18n.ServeLocalized()
+	})
 })
@@ -16,7 +16,8 @@ var _ = json.Unmarshal([]byte(`{
 		"ApiCalcServiceCalcMethodAddDescription":"Add adds up the two integer parameters and returns the results.",
 		"ApiCalcServiceCalcMethodAddPayloadAttributeADescription":"Left operand",
 		"ApiCalcServiceCalcMethodAddPayloadAttributeBDescription":"Right operand",
-		"ApiCalcServiceCalcMethodAddResultDescription":"Result of addition"
+		"ApiCalcServiceCalcMethodAddResultDescription":"Result of addition",
+		"ApiCalcErrorInvalidFieldType":"{field} must be an integer"
 	},
 	"nl": {
 		"ApiCalcTitle":"CORS Voorbeeld Calc API",
@@ -25,7 +26,8 @@ var _ = json.Unmarshal([]byte(`{
 		"ApiCalcServiceCalcMethodAddDescription":"Tel twee getallen bij elkaar op en retourneerd het resultaat.",
 		"ApiCalcServiceCalcMethodAddPayloadAttributeADescription":"Linker operand",
 		"ApiCalcServiceCalcMethodAddPayloadAttributeBDescription":"Rechter operand",
-		"ApiCalcServiceCalcMethodAddResultDescription":"Resultaat van optellen"
+		"ApiCalcServiceCalcMethodAddResultDescription":"Resultaat van optellen",
+		"ApiCalcErrorInvalidFieldType":"{field} moet een geheel getal zijn"
 	}
 }`), &messages)
 
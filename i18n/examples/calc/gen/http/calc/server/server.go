@@ -14,13 +14,15 @@ import (
 
 	goahttp "goa.design/goa/v3/http"
 	goa "goa.design/goa/v3/pkg"
+	goai18n "goa.design/plugins/v3/i18n"
 	calc "goa.design/plugins/v3/i18n/examples/calc/gen/calc"
 )
 
 // Server lists the calc service endpoint HTTP handlers.
 type Server struct {
-	Mounts []*MountPoint
-	Add    http.Handler
+	Mounts              []*MountPoint
+	Add                 http.Handler
+	GenHTTPOpenapi3JSON http.Handler
 }
 
 // ErrorNamer is an interface implemented by generated error structs that
@@ -53,12 +55,18 @@ func New(
 	encoder func(context.Context, http.ResponseWriter) goahttp.Encoder,
 	errhandler func(context.Context, http.ResponseWriter, error),
 	formatter func(err error) goahttp.Statuser,
+	fileSystemGenHTTPOpenapi3JSON http.FileSystem,
 ) *Server {
+	if fileSystemGenHTTPOpenapi3JSON == nil {
+		fileSystemGenHTTPOpenapi3JSON = http.Dir(".")
+	}
 	return &Server{
 		Mounts: []*MountPoint{
 			{"Add", "GET", "/add/{a}/{b}"},
+			{"./gen/http/openapi3.json", "GET", "/openapi3.json"},
 		},
-		Add: NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
+		Add:                 NewAddHandler(e.Add, mux, decoder, encoder, errhandler, formatter),
+		GenHTTPOpenapi3JSON: goai18n.LocalizedFiles(http.FileServer(fileSystemGenHTTPOpenapi3JSON), "en", "nl"),
 	}
 }
 
@@ -73,6 +81,7 @@ func (s *Server) Use(m func(http.Handler) http.Handler) {
 // Mount configures the mux to serve the calc endpoints.
 func Mount(mux goahttp.Muxer, h *Server) {
 	MountAddHandler(mux, h.Add)
+	MountGenHTTPOpenapi3JSON(mux, goahttp.Replace("", "/./gen/http/openapi3.json", h.GenHTTPOpenapi3JSON))
 }
 
 // Mount configures the mux to serve the calc endpoints.
@@ -130,3 +139,9 @@ func NewAddHandler(
 		}
 	})
 }
+
+// MountGenHTTPOpenapi3JSON configures the mux to serve GET request made to
+// "/openapi3.json".
+func MountGenHTTPOpenapi3JSON(mux goahttp.Muxer, h http.Handler) {
+	mux.Handle("GET", "/openapi3.json", h.ServeHTTP)
+}
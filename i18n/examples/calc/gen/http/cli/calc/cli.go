@@ -46,8 +46,8 @@ func ParseEndpoint(
 		calcFlags = flag.NewFlagSet("calc", flag.ContinueOnError)
 
 		calcAddFlags = flag.NewFlagSet("add", flag.ExitOnError)
-		calcAddAFlag = calcAddFlags.String("a", "REQUIRED", "")
-		calcAddBFlag = calcAddFlags.String("b", "REQUIRED", "")
+		calcAddAFlag = calcAddFlags.String("a", "REQUIRED", "Left operand")
+		calcAddBFlag = calcAddFlags.String("b", "REQUIRED", "Right operand")
 	)
 	calcFlags.Usage = calcUsage
 	calcAddFlags.Usage = calcAddUsage
@@ -144,8 +144,8 @@ func calcAddUsage() {
 	fmt.Fprintf(os.Stderr, `%[1]s [flags] calc add -a INT -b INT
 
 Add adds up the two integer parameters and returns the results.
-    -a INT: 
-    -b INT: 
+    -a INT: Left operand
+    -b INT: Right operand
 
 Example:
     %[1]s calc add --a 1 --b 2
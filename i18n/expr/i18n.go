@@ -4,15 +4,23 @@ import (
 	"fmt"
 
 	"goa.design/goa/v3/eval"
+	"goa.design/goa/v3/expr"
 )
 
 type Translateable = func(locale string) string
 type (
-	// I18nExpr describes a CORS policy.
+	// I18nExpr describes a translated text of an expression.
 	I18nExpr struct {
-		// Origin is the origin string.
+		// Name is the name of the translated text: "description",
+		// "example", "title", "enum", "message", "meta" or "tag".
+		Name string
+		// Trans lists the translations.
 		Trans []Translateable
-		// Parent expression, ServiceExpr or APIExpr.
+		// Value is the enum value documented by an "enum" translation,
+		// the error name of a "message" translation, the meta key of a
+		// "meta" translation or the tag name of a "tag" translation.
+		Value interface{}
+		// Parent is the translated expression.
 		Parent eval.Expression
 	}
 )
@@ -25,17 +33,99 @@ func (i18n *I18nExpr) Messages(locale string) []string {
 	return messages
 }
 
+// Translate returns the translated messages using the first locale of the
+// given fallback chain for which the translation is not missing. The messages
+// that are missing in all the locales are the messages of the first locale.
+func (i18n *I18nExpr) Translate(locales []string) []string {
+	messages := i18n.Messages(locales[0])
+	for i, t := range i18n.Trans {
+		for _, locale := range locales[1:] {
+			if !Root.IsMissing(messages[i]) {
+				break
+			}
+			if msg := t(locale); !Root.IsMissing(msg) {
+				messages[i] = msg
+			}
+		}
+	}
+	return messages
+}
+
 // EvalName returns the generic expression name used in error messages.
 func (i18n *I18nExpr) EvalName() string {
 	var suffix string
 	if i18n.Parent != nil {
 		suffix = fmt.Sprintf(" of %s", i18n.Parent.EvalName())
 	}
-	return "I18N" + suffix
+	var value string
+	if i18n.Value != nil {
+		value = fmt.Sprintf(" %#v", i18n.Value)
+	}
+	return "I18N " + i18n.Name + value + suffix
 }
 
-// Validate ensures the origin expression is valid.
+// Validate ensures the translated expression supports the translated text.
 func (i18n *I18nExpr) Validate() *eval.ValidationErrors {
 	verr := new(eval.ValidationErrors)
+	switch i18n.Name {
+	case "description":
+		switch i18n.Parent.(type) {
+		case *expr.APIExpr, *expr.ServerExpr, *expr.HostExpr, *expr.ServiceExpr,
+			*expr.ResultTypeExpr, *expr.AttributeExpr, *expr.DocsExpr,
+			*expr.MethodExpr, *expr.SchemeExpr, *expr.HTTPResponseExpr,
+			*expr.HTTPFileServerExpr, *expr.GRPCResponseExpr:
+		default:
+			verr.Add(i18n, "description cannot be translated in %s", evalName(i18n.Parent))
+		}
+	case "example":
+		if _, ok := i18n.Parent.(*expr.AttributeExpr); !ok {
+			verr.Add(i18n, "example cannot be translated in %s", evalName(i18n.Parent))
+		}
+		if len(i18n.Trans) == 0 || len(i18n.Trans) > 2 {
+			verr.Add(i18n, "example must be given a value and an optional summary")
+		}
+	case "title":
+		if _, ok := i18n.Parent.(*expr.APIExpr); !ok {
+			verr.Add(i18n, "title cannot be translated in %s", evalName(i18n.Parent))
+		}
+	case "message":
+		if _, ok := i18n.Parent.(*expr.APIExpr); !ok {
+			verr.Add(i18n, "error messages can only be translated in the API, got %s", evalName(i18n.Parent))
+		}
+	case "meta", "tag":
+		if MetaOf(i18n.Parent) == nil {
+			verr.Add(i18n, "meta cannot be translated in %s", evalName(i18n.Parent))
+		}
+	case "enum":
+		att, ok := i18n.Parent.(*expr.AttributeExpr)
+		if !ok {
+			verr.Add(i18n, "enum values can only be documented in attributes, got %s", evalName(i18n.Parent))
+			break
+		}
+		if !isEnumValue(att, i18n.Value) {
+			verr.Add(i18n, "%#v is not an enum value of the attribute", i18n.Value)
+		}
+	}
 	return verr
 }
+
+// isEnumValue returns true if v is one of the enum values of att.
+func isEnumValue(att *expr.AttributeExpr, v interface{}) bool {
+	if att.Validation == nil {
+		return false
+	}
+	for _, ev := range att.Validation.Values {
+		if fmt.Sprint(ev) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// evalName returns the name of e used in error messages.
+func evalName(e eval.Expression) string {
+	if e == nil {
+		return "top level"
+	}
+	return e.EvalName()
+}
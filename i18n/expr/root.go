@@ -1,6 +1,10 @@
 package expr
 
 import (
+	"regexp"
+	"strconv"
+	"strings"
+
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
 )
@@ -10,8 +14,16 @@ var Root = &RootExpr{
 	Description: map[eval.Expression]*I18nExpr{},
 	Example:     map[eval.Expression]*I18nExpr{},
 	Title:       map[eval.Expression]*I18nExpr{},
+	Enum:        map[eval.Expression][]*I18nExpr{},
+	Meta:        map[eval.Expression][]*I18nExpr{},
+	Messages:    map[string]*I18nExpr{},
 }
 
+// MetaKey is the meta key set on the translated attributes, HTTP responses and
+// security schemes. Goa copies these expressions when finalizing the design, the
+// key makes it possible to translate the copies.
+const MetaKey = "i18n:key"
+
 type (
 	// RootExpr keeps track of the CORS origins defined in the design.
 	RootExpr struct {
@@ -20,6 +32,36 @@ type (
 		Description map[eval.Expression]*I18nExpr
 		Example     map[eval.Expression]*I18nExpr
 		Title       map[eval.Expression]*I18nExpr
+		// Enum lists the translated documentation of the enum values
+		// indexed by attribute.
+		Enum map[eval.Expression][]*I18nExpr
+		// Meta lists the translated meta values and tag descriptions
+		// indexed by expression.
+		Meta map[eval.Expression][]*I18nExpr
+		// Messages lists the translated error messages indexed by error
+		// name.
+		Messages map[string]*I18nExpr
+		// Locales lists the locales defined with the Locales DSL, the
+		// first locale is the default locale.
+		Locales []string
+		// DesignDir is the directory used to lookup the locale file, it
+		// defaults to the directory of the design package given to the
+		// goa command.
+		DesignDir string
+		// Strict is true if the generation fails when a translation is
+		// missing.
+		Strict bool
+		// Report is true if the report of the missing translations is
+		// generated.
+		Report bool
+		// Placeholder matches the translations returned for missing
+		// translations.
+		Placeholder *regexp.Regexp
+		// Extract lists the formats of the extracted translation catalogs.
+		Extract []string
+		// LocalizedFiles lists the file servers serving the files of
+		// the locale negotiated with the Accept-Language header.
+		LocalizedFiles []*expr.HTTPFileServerExpr
 	}
 )
 
@@ -33,16 +75,134 @@ func (r *RootExpr) EvalName() string {
 	return "I18n plugin"
 }
 
-// WalkSets iterates over the API-level and service-level I18n definitions.
+// WalkSets iterates over the I18n definitions.
 func (r *RootExpr) WalkSets(walk eval.SetWalker) {
-	oexps := make(eval.ExpressionSet, 0, len(r.Description))
-	for _, o := range r.Description {
+	var oexps eval.ExpressionSet
+	for _, m := range []map[eval.Expression]*I18nExpr{r.Description, r.Example, r.Title} {
+		for _, o := range m {
+			oexps = append(oexps, o)
+		}
+	}
+	for _, m := range []map[eval.Expression][]*I18nExpr{r.Enum, r.Meta} {
+		for _, os := range m {
+			for _, o := range os {
+				oexps = append(oexps, o)
+			}
+		}
+	}
+	for _, o := range r.Messages {
 		oexps = append(oexps, o)
 	}
 	walk(oexps)
+}
+
+// Key returns the value of the meta key identifying the translations of e or
+// an empty string if e does not have one.
+func Key(e eval.Expression) string {
+	meta := metaOf(e)
+	if meta == nil {
+		return ""
+	}
+	if key, ok := (*meta)[MetaKey]; ok && len(key) > 0 {
+		return key[0]
+	}
+	return ""
+}
+
+// SetKey sets the meta key identifying the translations of e if e is an
+// attribute, a HTTP response or a security scheme that does not have one yet.
+func SetKey(e eval.Expression) {
+	meta := metaOf(e)
+	if meta == nil || Key(e) != "" {
+		return
+	}
+	if *meta == nil {
+		*meta = expr.MetaExpr{}
+	}
+	keys++
+	(*meta)[MetaKey] = []string{strconv.Itoa(keys)}
+}
+
+// metaOf returns the meta of the expressions that Goa copies.
+func metaOf(e eval.Expression) *expr.MetaExpr {
+	switch actual := e.(type) {
+	case *expr.AttributeExpr:
+		return &actual.Meta
+	case *expr.HTTPResponseExpr:
+		return &actual.Meta
+	case *expr.SchemeExpr:
+		return &actual.Meta
+	}
+	return nil
+}
+
+// DescriptionOf returns a pointer to the description of the given expression,
+// nil if the expression does not have a description.
+func DescriptionOf(e eval.Expression) *string {
+	switch actual := e.(type) {
+	case *expr.APIExpr:
+		return &actual.Description
+	case *expr.ServerExpr:
+		return &actual.Description
+	case *expr.HostExpr:
+		return &actual.Description
+	case *expr.ServiceExpr:
+		return &actual.Description
+	case *expr.ResultTypeExpr:
+		return &actual.Description
+	case *expr.AttributeExpr:
+		return &actual.Description
+	case *expr.DocsExpr:
+		return &actual.Description
+	case *expr.MethodExpr:
+		return &actual.Description
+	case *expr.ExampleExpr:
+		return &actual.Description
+	case *expr.SchemeExpr:
+		return &actual.Description
+	case *expr.HTTPResponseExpr:
+		return &actual.Description
+	case *expr.HTTPFileServerExpr:
+		return &actual.Description
+	case *expr.GRPCResponseExpr:
+		return &actual.Description
+	}
+	return nil
+}
 
+// MetaOf returns the meta of the expressions supporting the Goa Meta DSL, nil
+// if e does not support it.
+func MetaOf(e eval.Expression) *expr.MetaExpr {
+	switch actual := e.(type) {
+	case *expr.APIExpr:
+		return &actual.Meta
+	case *expr.AttributeExpr:
+		return &actual.Meta
+	case *expr.ResultTypeExpr:
+		return &actual.Meta
+	case *expr.MethodExpr:
+		return &actual.Meta
+	case *expr.ServiceExpr:
+		return &actual.Meta
+	case *expr.HTTPServiceExpr:
+		return &actual.Meta
+	case *expr.HTTPEndpointExpr:
+		return &actual.Meta
+	case *expr.RouteExpr:
+		return &actual.Meta
+	case *expr.HTTPFileServerExpr:
+		return &actual.Meta
+	case *expr.HTTPResponseExpr:
+		return &actual.Meta
+	case *expr.SchemeExpr:
+		return &actual.Meta
+	}
+	return nil
 }
 
+// keys counts the keys set by SetKey.
+var keys int
+
 // DependsOn tells the eval engine to run the goa DSL first.
 func (r *RootExpr) DependsOn() []eval.Root {
 	return []eval.Root{expr.Root}
@@ -54,3 +214,12 @@ func (r *RootExpr) DependsOn() []eval.Root {
 func (r *RootExpr) Packages() []string {
 	return []string{"goa.design/plugins/v3/i18n/dsl"}
 }
+
+// IsMissing returns true if the translation is empty or matches the
+// placeholder defined in the design.
+func (r *RootExpr) IsMissing(msg string) bool {
+	if strings.TrimSpace(msg) == "" {
+		return true
+	}
+	return r.Placeholder != nil && r.Placeholder.MatchString(msg)
+}
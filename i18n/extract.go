@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"goa.design/goa/v3/codegen"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/i18n/catalogs"
+	"goa.design/plugins/v3/i18n/expr"
+)
+
+// extractFiles returns the catalogs listing the translations of each locale
+// in the formats given to the Extract DSL. The first locale is the default
+// locale which provides the source texts. Missing translations are empty. It
+// returns an error if a catalog cannot be written.
+func extractFiles(root *goaexpr.RootExpr, locales []string, formats []string) ([]*codegen.File, error) {
+	ts := translations(root)
+	var files []*codegen.File
+	for _, locale := range locales {
+		f := &catalogs.File{Locale: locale, SourceLocale: locales[0]}
+		for _, t := range ts {
+			source := t.I18nExpr.Messages(locales[0])[t.Index]
+			target := t.I18nExpr.Messages(locale)[t.Index]
+			if expr.Root.IsMissing(target) {
+				target = ""
+			}
+			f.Entries = append(f.Entries, &catalogs.Entry{
+				ID:          t.ID,
+				Description: t.I18nExpr.EvalName(),
+				Source:      source,
+				Target:      target,
+			})
+		}
+		for _, format := range formats {
+			var buf bytes.Buffer
+			if err := catalogs.Write(&buf, format, f); err != nil {
+				return nil, fmt.Errorf("i18n: failed to write %s catalog of locale %q: %w", format, locale, err)
+			}
+			files = append(files, &codegen.File{
+				Path: filepath.Join(codegen.Gendir, "i18n", locale+catalogs.Ext(format)),
+				SectionTemplates: []*codegen.SectionTemplate{{
+					Name:   "i18n-catalog-" + format,
+					Source: "{{ . }}",
+					Data:   buf.String(),
+				}},
+			})
+		}
+	}
+	return files, nil
+}
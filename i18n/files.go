@@ -0,0 +1,168 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"goa.design/goa/v3/codegen"
+	httpcodegen "goa.design/goa/v3/http/codegen"
+	"goa.design/plugins/v3/i18n/expr"
+	"goa.design/plugins/v3/internal/vary"
+)
+
+// LocalizedFiles returns a handler serving with the file server h the files
+// of the locale best matching the Accept-Language header of the requests. The
+// first locale is the default locale, the files of the other locales are named
+// after the files of the default locale with the locale appended before their
+// extension as generated by the plugin, e.g. openapi3_nl.json. The file of
+// the default locale is served if h does not find the file of the locale. The
+// handler sets the Content-Language response header to the language tag of
+// the locale and adds Accept-Language to the Vary response header.
+func LocalizedFiles(h http.Handler, locales ...string) http.Handler {
+	c := &Catalog{Locales: locales}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vary.Add(w.Header(), "Accept-Language")
+		locale := c.Negotiate(r.Header.Get("Accept-Language"))
+		if locale == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if locale != locales[0] {
+			lr := r.Clone(r.Context())
+			dir, name := path.Split(lr.URL.Path)
+			lr.URL.Path = dir + localizedName(name, locale)
+			lr.URL.RawPath = ""
+			lw := &localizedWriter{w: w, header: w.Header().Clone()}
+			lw.header.Set("Content-Language", tagOf(locale))
+			h.ServeHTTP(lw, lr)
+			if !lw.notFound {
+				return
+			}
+		}
+		w.Header().Set("Content-Language", tagOf(locales[0]))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// localizedWriter is the response writer used to serve the file of a locale.
+// It discards the not found responses so that the file of the default locale
+// can be served instead.
+type localizedWriter struct {
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	notFound    bool
+}
+
+// Header returns the response headers, they are copied to the underlying
+// response writer unless the response is a not found response.
+func (w *localizedWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader writes the response status code and headers to the underlying
+// response writer unless code is http.StatusNotFound.
+func (w *localizedWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if code == http.StatusNotFound {
+		w.notFound = true
+		return
+	}
+	h := w.w.Header()
+	for k, v := range w.header {
+		h[k] = v
+	}
+	w.w.WriteHeader(code)
+}
+
+// Write writes b to the underlying response writer unless the response is a
+// not found response.
+func (w *localizedWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.notFound {
+		return len(b), nil
+	}
+	return w.w.Write(b)
+}
+
+// localizeFileServers wraps the file servers of the generated HTTP servers
+// whose files are served with the ServeLocalized DSL with LocalizedFiles. It
+// returns an error if the goa template initializing the file servers cannot be
+// modified.
+func localizeFileServers(files []*codegen.File, locales []string) error {
+	if len(expr.Root.LocalizedFiles) == 0 {
+		return nil
+	}
+	args := make([]string, len(locales))
+	for i, l := range locales {
+		args[i] = fmt.Sprintf("%q", l)
+	}
+	for _, f := range files {
+		if filepath.Base(f.Path) != "server.go" {
+			continue
+		}
+		localized := make(map[string]bool)
+		for _, s := range f.Section("server-struct") {
+			data, ok := s.Data.(*httpcodegen.ServiceData)
+			if !ok {
+				continue
+			}
+			for _, fsd := range data.FileServers {
+				for _, fs := range expr.Root.LocalizedFiles {
+					if fs.Service.Name() == data.Service.Name && fs.FilePath == fsd.FilePath {
+						localized[fsd.VarName] = true
+					}
+				}
+			}
+		}
+		if len(localized) == 0 {
+			continue
+		}
+		codegen.AddImport(f.SectionTemplates[0],
+			&codegen.ImportSpec{Name: "goai18n", Path: "goa.design/plugins/v3/i18n"})
+		for _, s := range f.Section("server-init") {
+			if err := wrapFileServer(s, func(h string) string {
+				return `{{ if i18nLocalized .VarName }}goai18n.LocalizedFiles(` + h + `, ` + strings.Join(args, ", ") + `){{ else }}` + h + `{{ end }}`
+			}); err != nil {
+				return err
+			}
+			fm := make(map[string]interface{}, len(s.FuncMap)+1)
+			for k, v := range s.FuncMap {
+				fm[k] = v
+			}
+			fm["i18nLocalized"] = func(varName string) bool { return localized[varName] }
+			s.FuncMap = fm
+		}
+	}
+	return nil
+}
+
+// wrapFileServer replaces the expression initializing the file server handlers
+// in the given goa server init section with the result of wrap. The whole
+// expression is wrapped so that the generated code does not depend on the
+// order in which the plugins wrapping the file server handlers (e.g. CORS) are
+// run. It returns an error if the section does not initialize file server
+// handlers.
+func wrapFileServer(s *codegen.SectionTemplate, wrap func(string) string) error {
+	const init = "{{ .VarName }}: "
+	start := strings.Index(s.Source, init)
+	if start < 0 {
+		return fmt.Errorf("i18n: cannot find %q in goa section %q, the goa version is not supported", init, s.Name)
+	}
+	start += len(init)
+	end := strings.Index(s.Source[start:], ",\n")
+	if end < 0 {
+		return fmt.Errorf("i18n: cannot find the end of the file server handler in goa section %q, the goa version is not supported", s.Name)
+	}
+	end += start
+	s.Source = s.Source[:start] + wrap(s.Source[start:end]) + s.Source[end:]
+	return nil
+}
@@ -0,0 +1,86 @@
+package i18n_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"goa.design/plugins/v3/i18n"
+)
+
+func TestLocalizedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"openapi3.json":       "en",
+		"openapi3_nl.json":    "nl",
+		"openapi3_de_AT.json": "de_AT",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := i18n.LocalizedFiles(http.FileServer(http.Dir(dir)), "en", "nl", "de_AT")
+	cases := []struct {
+		Name                    string
+		AcceptLanguage          string
+		ExpectedBody            string
+		ExpectedContentLanguage string
+	}{
+		{"none", "", "en", "en"},
+		{"default", "en-US", "en", "en"},
+		{"locale", "nl-NL;q=0.8, fr", "nl", "nl"},
+		{"tag", "de-AT", "de_AT", "de-AT"},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/openapi3.json", nil)
+			req.Header.Set("Accept-Language", c.AcceptLanguage)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+			}
+			if body := w.Body.String(); body != c.ExpectedBody {
+				t.Errorf("got body %q, expected %q", body, c.ExpectedBody)
+			}
+			if cl := w.Header().Get("Content-Language"); cl != c.ExpectedContentLanguage {
+				t.Errorf("got Content-Language %q, expected %q", cl, c.ExpectedContentLanguage)
+			}
+			if v := w.Header().Get("Vary"); v != "Accept-Language" {
+				t.Errorf("got Vary %q, expected %q", v, "Accept-Language")
+			}
+		})
+	}
+}
+
+func TestLocalizedFilesFallback(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "doc.html"), []byte("en"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h := i18n.LocalizedFiles(http.FileServer(http.Dir(dir)), "en", "nl")
+	req := httptest.NewRequest("GET", "/doc.html", nil)
+	req.Header.Set("Accept-Language", "nl")
+	w := httptest.NewRecorder()
+	w.Header().Set("Vary", "Origin")
+
+	h.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+	}
+	if body := w.Body.String(); body != "en" {
+		t.Errorf("got body %q, expected %q", body, "en")
+	}
+	if cl := w.Header().Get("Content-Language"); cl != "en" {
+		t.Errorf("got Content-Language %q, expected %q", cl, "en")
+	}
+	if ct := w.Header().Get("X-Content-Type-Options"); ct != "" {
+		t.Errorf("got X-Content-Type-Options %q from the not found response", ct)
+	}
+	if v := w.Header().Values("Vary"); len(v) != 2 || v[0] != "Origin" || v[1] != "Accept-Language" {
+		t.Errorf("got Vary %v, expected [Origin Accept-Language]", v)
+	}
+}
@@ -1,12 +1,15 @@
 package i18n
 
 import (
+	"encoding/json"
 	"fmt"
+	"go/build"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 
 	"goa.design/goa/v3/codegen"
-	goadsl "goa.design/goa/v3/dsl"
 	"goa.design/goa/v3/eval"
 
 	goaexpr "goa.design/goa/v3/expr"
@@ -18,17 +21,156 @@ func init() {
 	codegen.RegisterPlugin("i18n", "gen", Prepare, Generate)
 }
 
+// generator is a generator of artifacts produced for each locale.
+type generator struct {
+	// name identifies the generator.
+	name string
+	// fn generates the artifacts.
+	fn codegen.GenerateFunc
+}
+
+// generators lists the generators run for each locale other than the default
+// locale in registration order.
+var generators = []*generator{{name: "openapi", fn: openapiFiles}}
+
+// RegisterGenerator registers a generator of artifacts that is run again for
+// each locale other than the default locale once the design is translated in
+// that locale, e.g. the generator of the docs plugin:
+//
+//	i18n.RegisterGenerator("docs", docs.Generate)
+//
+// The generator is given no files and must return the generated artifacts
+// only. The locale is appended to the name of the artifacts before their
+// extension, e.g. gen/docs_nl.json. The generators should not produce Go
+// files as the localized files would be compiled in the same package.
+// Registering a generator with the name of a registered generator replaces
+// it, a nil generator unregisters it. The OpenAPI specifications are
+// generated by the "openapi" generator, the generators of the other plugins
+// are not registered automatically.
+func RegisterGenerator(name string, fn codegen.GenerateFunc) {
+	for i, g := range generators {
+		if g.name != name {
+			continue
+		}
+		if fn == nil {
+			generators = append(generators[:i], generators[i+1:]...)
+		} else {
+			g.fn = fn
+		}
+		return
+	}
+	if fn != nil {
+		generators = append(generators, &generator{name: name, fn: fn})
+	}
+}
+
+// openapiFiles generates the OpenAPI specifications.
+func openapiFiles(_ string, roots []eval.Root, _ []*codegen.File) ([]*codegen.File, error) {
+	var files []*codegen.File
+	for _, root := range roots {
+		if r, ok := root.(*goaexpr.RootExpr); ok {
+			fs, err := httpcodegen.OpenAPIFiles(r)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, fs...)
+		}
+	}
+	return files, nil
+}
+
+// localizedPath returns path with the locale appended to the file name before
+// its extension, e.g. gen/http/openapi3_nl.yaml.
+func localizedPath(path, locale string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, localizedName(name, locale))
+}
+
+// localizedName returns the file name with the locale appended before its
+// extension, e.g. openapi3_nl.yaml.
+func localizedName(name, locale string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_" + locale + ext
+}
+
 // ENVKEY is the key used to lookup locales to use when producing translation openapi specs
 const ENVKEY = "GOA_I18N"
 
+// LOCALEFILE is the name of the file listing the locales in the directory of
+// the design
+const LOCALEFILE = "i18n.locales"
+
+// getLocales returns the locales listed in the GOA_I18N environment variable,
+// in the locale file or defined with the Locales DSL in this order of
+// precedence. The first locale is the default locale. It returns an error if a
+// locale is not a BCP 47 language tag.
 func getLocales() ([]string, error) {
-	locales := os.Getenv(ENVKEY)
+	locales, err := readLocales()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLocales(locales); err != nil {
+		return nil, err
+	}
+	return locales, nil
+}
 
-	if locales == "" {
-		return nil, fmt.Errorf("Environment variable \"GOA_I18N\" not found, this is required to generate locale dependend output")
+// readLocales returns the configured locales.
+func readLocales() ([]string, error) {
+	if locales := parseLocales(os.Getenv(ENVKEY)); len(locales) > 0 {
+		return locales, nil
+	}
+	dir := expr.Root.DesignDir
+	if dir == "" {
+		dir = designDir()
 	}
+	if dir != "" {
+		b, err := os.ReadFile(filepath.Join(dir, LOCALEFILE))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if locales := parseLocales(string(b)); len(locales) > 0 {
+			return locales, nil
+		}
+	}
+	if locales := parseLocales(strings.Join(expr.Root.Locales, ",")); len(locales) > 0 {
+		return locales, nil
+	}
+	return nil, fmt.Errorf("no locale defined, set the environment variable %q, create the file %q in the design package directory or use the Locales DSL to generate locale dependend output", ENVKEY, LOCALEFILE)
+}
 
-	return strings.Split(locales, ","), nil
+// designDir returns the directory of the design package given to the goa
+// command, e.g. "goa gen calc/design". It returns an empty string if the
+// command line does not list the design package or if the package cannot be
+// found.
+func designDir() string {
+	args := strings.Fields(codegen.CommandLine())
+	if len(args) < 4 || args[0] != "$" {
+		return ""
+	}
+	pkg, err := build.Import(args[3], ".", build.FindOnly)
+	if err != nil {
+		return ""
+	}
+	return pkg.Dir
+}
+
+// parseLocales returns the locales listed in s separated with commas or new
+// lines. Lines starting with "#" are ignored.
+func parseLocales(s string) []string {
+	var locales []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, l := range strings.Split(line, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				locales = append(locales, l)
+			}
+		}
+	}
+	return locales
 }
 
 // Prepare executes all translations with the default language
@@ -39,74 +181,403 @@ func Prepare(genpkg string, roots []eval.Root) error {
 		return error
 	}
 
-	defaultLocale := locales[0]
-	walkTranslations(roots, defaultLocale)
+	for _, root := range roots {
+		if r, ok := root.(*goaexpr.RootExpr); ok {
+			walkTranslations(r, locales[:1], func(e eval.Expression) eval.Expression { return e })
+		}
+	}
 	return nil
 }
 
-func walkTranslations(roots []eval.Root, locale string) {
-	walker := func(s eval.ExpressionSet) error {
-		i18nRoot := expr.Root
+// walkTranslations applies the translations of the first locale of the given
+// fallback chain to the translated expressions of the design and to their
+// copies made by Goa when finalizing the design. The missing translations are
+// looked up in the next locales of the chain. lookup returns the expression of
+// root corresponding to a translated expression, nil if there is none.
+func walkTranslations(root *goaexpr.RootExpr, locales []string, lookup func(eval.Expression) eval.Expression) {
+	setLocaleExtension(root.API, locales[0])
+	copies := make(map[string][]eval.Expression)
+	walkCopies(root, func(key string, e eval.Expression) {
+		copies[key] = append(copies[key], e)
+	})
+	targets := func(e eval.Expression) []eval.Expression {
+		var ts []eval.Expression
+		if t := lookup(e); t != nil {
+			ts = append(ts, t)
+		}
+		if key := expr.Key(e); key != "" {
+			for _, c := range copies[key] {
+				if len(ts) == 0 || c != ts[0] {
+					ts = append(ts, c)
+				}
+			}
+		}
+		return ts
+	}
+	described := make(map[eval.Expression]struct{})
+	for e := range expr.Root.Description {
+		for _, t := range targets(e) {
+			described[t] = struct{}{}
+		}
+	}
+	i18nRoot := expr.Root
+	for e, i18nExpr := range i18nRoot.Description {
+		for _, t := range targets(e) {
+			if herr, ok := t.(*goaexpr.HTTPErrorExpr); ok {
+				if _, ok := described[herr.Response]; !ok {
+					handleErrorTranslation(herr, i18nExpr.Translate(locales))
+				}
+				continue
+			}
+			handleDescriptionTranslation(t, i18nExpr.Translate(locales))
+		}
+	}
+	for e, i18nExpr := range i18nRoot.Example {
+		for _, t := range targets(e) {
+			handleExampleTranslation(t, i18nExpr.Translate(locales))
+		}
+	}
+	for e, i18nExpr := range i18nRoot.Title {
+		if t := lookup(e); t != nil {
+			handleTitleTranslation(t, i18nExpr.Translate(locales))
+		}
+	}
+	for e, i18nExprs := range i18nRoot.Enum {
+		for _, t := range targets(e) {
+			handleEnumTranslation(t, i18nExprs, locales)
+		}
+	}
+	for e, i18nExprs := range i18nRoot.Meta {
+		for _, t := range targets(e) {
+			handleMetaTranslation(t, i18nExprs, locales)
+		}
+	}
+}
 
-		for _, e := range s {
-			if i18nExpr, ok := i18nRoot.Description[e]; ok {
-				handleDescriptionTranslation(e, i18nExpr.Messages(locale))
+// walkCopies calls fn with the i18n meta key of the attributes, HTTP responses
+// and security schemes of the design that have one. fn is also called with
+// the HTTP errors and the key of their error attribute.
+func walkCopies(root *goaexpr.RootExpr, fn func(string, eval.Expression)) {
+	seen := make(map[*goaexpr.AttributeExpr]struct{})
+	var walkAtt func(*goaexpr.AttributeExpr)
+	walkAtt = func(att *goaexpr.AttributeExpr) {
+		if att == nil {
+			return
+		}
+		if _, ok := seen[att]; ok {
+			return
+		}
+		seen[att] = struct{}{}
+		if key := expr.Key(att); key != "" {
+			fn(key, att)
+		}
+		switch dt := att.Type.(type) {
+		case goaexpr.UserType:
+			walkAtt(dt.Attribute())
+		case *goaexpr.Object:
+			for _, nat := range *dt {
+				walkAtt(nat.Attribute)
+			}
+		case *goaexpr.Array:
+			walkAtt(dt.ElemType)
+		case *goaexpr.Map:
+			walkAtt(dt.KeyType)
+			walkAtt(dt.ElemType)
+		case *goaexpr.Union:
+			for _, nat := range dt.Values {
+				walkAtt(nat.Attribute)
+			}
+		}
+	}
+	walkMapped := func(ma *goaexpr.MappedAttributeExpr) {
+		if ma != nil {
+			walkAtt(ma.AttributeExpr)
+		}
+	}
+	walkResp := func(r *goaexpr.HTTPResponseExpr) {
+		if r == nil {
+			return
+		}
+		if key := expr.Key(r); key != "" {
+			fn(key, r)
+		}
+		walkAtt(r.Body)
+		walkMapped(r.Headers)
+		walkMapped(r.Cookies)
+	}
+	walkErrors := func(errs []*goaexpr.HTTPErrorExpr) {
+		for _, e := range errs {
+			if key := expr.Key(e.AttributeExpr); key != "" {
+				fn(key, e)
 			}
-			if i18nExpr, ok := i18nRoot.Example[e]; ok {
-				handleExampleTranslation(e, i18nExpr.Messages(locale))
+			walkAtt(e.AttributeExpr)
+			walkResp(e.Response)
+		}
+	}
+	walkReqs := func(reqs []*goaexpr.SecurityExpr) {
+		for _, req := range reqs {
+			for _, sch := range req.Schemes {
+				if key := expr.Key(sch); key != "" {
+					fn(key, sch)
+				}
 			}
-			if i18nExpr, ok := i18nRoot.Title[e]; ok {
-				handleTitleTranslation(e, i18nExpr.Messages(locale))
+		}
+	}
+
+	for _, t := range root.Types {
+		walkAtt(t.Attribute())
+	}
+	for _, t := range root.ResultTypes {
+		walkAtt(t.Attribute())
+	}
+	if root.GeneratedTypes != nil {
+		for _, t := range *root.GeneratedTypes {
+			walkAtt(t.Attribute())
+		}
+	}
+	for _, e := range root.Errors {
+		walkAtt(e.AttributeExpr)
+	}
+	if root.API != nil {
+		walkReqs(root.API.Requirements)
+	}
+	for _, svc := range root.Services {
+		walkReqs(svc.Requirements)
+		for _, e := range svc.Errors {
+			walkAtt(e.AttributeExpr)
+		}
+		for _, m := range svc.Methods {
+			walkReqs(m.Requirements)
+			walkAtt(m.Payload)
+			walkAtt(m.StreamingPayload)
+			walkAtt(m.Result)
+			for _, e := range m.Errors {
+				walkAtt(e.AttributeExpr)
 			}
 		}
-		return nil
 	}
-	for _, root := range roots {
-		root.WalkSets(walker)
+	if root.API == nil || root.API.HTTP == nil {
+		return
+	}
+	walkErrors(root.API.HTTP.Errors)
+	for _, svc := range root.API.HTTP.Services {
+		walkMapped(svc.Params)
+		walkMapped(svc.Headers)
+		walkMapped(svc.Cookies)
+		walkErrors(svc.HTTPErrors)
+		for _, e := range svc.HTTPEndpoints {
+			walkReqs(e.Requirements)
+			walkMapped(e.Params)
+			walkMapped(e.Headers)
+			walkMapped(e.Cookies)
+			walkAtt(e.Body)
+			walkAtt(e.StreamingBody)
+			for _, r := range e.Responses {
+				walkResp(r)
+			}
+			walkErrors(e.HTTPErrors)
+		}
 	}
 }
 
 func handleDescriptionTranslation(p eval.Expression, d []string) {
-	eval.Execute(func() {
-		goadsl.Description(d[0])
-	}, p)
+	if desc := expr.DescriptionOf(p); desc != nil {
+		*desc = d[0]
+	}
 }
 
+// handleErrorTranslation sets the description of the HTTP response of the
+// given error to the error description. Goa uses the error description when
+// the response has none but this is done only once when generating the default
+// OpenAPI specifications.
+func handleErrorTranslation(herr *goaexpr.HTTPErrorExpr, d []string) {
+	if herr.Response == nil {
+		return
+	}
+	herr.Response.Description = d[0]
+}
+
+// handleExampleTranslation sets the value of the example of the attribute with
+// the same summary as the translated example. The example is added if the
+// attribute does not have one yet.
 func handleExampleTranslation(p eval.Expression, e []string) {
-	eval.Execute(func() {
-		goadsl.Example(e)
-	}, p)
+	att, ok := p.(*goaexpr.AttributeExpr)
+	if !ok || len(e) == 0 {
+		return
+	}
+	summary, value := "default", e[0]
+	if len(e) > 1 {
+		summary, value = e[0], e[1]
+	}
+	for _, ex := range att.UserExamples {
+		if ex.Summary == summary {
+			ex.Value = value
+			return
+		}
+	}
+	att.UserExamples = append(att.UserExamples, &goaexpr.ExampleExpr{Summary: summary, Value: value})
 }
+
 func handleTitleTranslation(p eval.Expression, e []string) {
-	eval.Execute(func() {
-		goadsl.Title(e[0])
-	}, p)
+	if api, ok := p.(*goaexpr.APIExpr); ok {
+		api.Title = e[0]
+	}
+}
+
+// handleEnumTranslation sets the x-enum-descriptions extension of the
+// attribute to the descriptions of its enum values in the given fallback
+// chain. The descriptions of values that are not translated are empty.
+func handleEnumTranslation(p eval.Expression, i18nExprs []*expr.I18nExpr, locales []string) {
+	att, ok := p.(*goaexpr.AttributeExpr)
+	if !ok || att.Validation == nil {
+		return
+	}
+	descs := make([]string, len(att.Validation.Values))
+	for i, v := range att.Validation.Values {
+		for _, i18nExpr := range i18nExprs {
+			if fmt.Sprint(i18nExpr.Value) == fmt.Sprint(v) {
+				descs[i] = i18nExpr.Translate(locales)[0]
+			}
+		}
+	}
+	b, err := json.Marshal(descs)
+	if err != nil {
+		return
+	}
+	if att.Meta == nil {
+		att.Meta = goaexpr.MetaExpr{}
+	}
+	att.Meta["openapi:extension:x-enum-descriptions"] = []string{string(b)}
+}
+
+// handleMetaTranslation sets the translated meta values and tag descriptions
+// of the expression in the given fallback chain.
+func handleMetaTranslation(p eval.Expression, i18nExprs []*expr.I18nExpr, locales []string) {
+	meta := expr.MetaOf(p)
+	if meta == nil {
+		return
+	}
+	if *meta == nil {
+		*meta = goaexpr.MetaExpr{}
+	}
+	for _, i18nExpr := range i18nExprs {
+		key := i18nExpr.Value.(string)
+		if i18nExpr.Name == "tag" {
+			key = tagKey(*meta, key)
+		}
+		(*meta)[key] = []string{i18nExpr.Translate(locales)[0]}
+	}
+}
+
+// tagKey returns the meta key of the description of the OpenAPI tag with the
+// given name. The tag is defined in meta if meta does not define it.
+func tagKey(meta goaexpr.MetaExpr, tag string) string {
+	for _, prefix := range []string{"openapi:tag:", "swagger:tag:"} {
+		if _, ok := meta[prefix+tag]; ok {
+			return prefix + tag + ":desc"
+		}
+	}
+	meta["openapi:tag:"+tag] = nil
+	return "openapi:tag:" + tag + ":desc"
 }
 
 // Generate produces additional openapi files for locales configured via
-// the system environment variable GOA_I18N
+// the system environment variable GOA_I18N, the locale file or the Locales DSL
 func Generate(genpkg string, roots []eval.Root, files []*codegen.File) ([]*codegen.File, error) {
-	locales, _ := getLocales()
+	locales, err := getLocales()
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Root.Strict || expr.Root.Report {
+		report := buildReport(goaexpr.Root, locales)
+		if expr.Root.Strict {
+			if err := checkStrict(locales, report); err != nil {
+				return nil, err
+			}
+		}
+		if expr.Root.Report {
+			f, err := reportFile(report)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+		}
+	}
+	if f := catalogFile(goaexpr.Root, locales); f != nil {
+		files = append(files, f)
+	}
+	if len(expr.Root.Extract) > 0 {
+		fs, err := extractFiles(goaexpr.Root, locales, expr.Root.Extract)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
+	}
+	if err := localizeFileServers(files, locales); err != nil {
+		return nil, err
+	}
 
 	if len(locales) <= 1 {
 		// Nothing to generate, default already contains translations of default locale
 		return files, nil
 	}
-	defaultLocale := locales[0]
 	restLocales := locales[1:]
 
-	for _, locale := range restLocales {
-		walkTranslations(roots, locale)
+	// The design is copied and translated in each locale concurrently so
+	// that the design read by Goa and the other plugins is not modified.
+	// The generators are run one locale after the other as the Goa DSL
+	// engine and OpenAPI generator rely on package level state.
+	snaps := make([]*snapshot, len(restLocales))
+	errs := make([]error, len(restLocales))
+	var wg sync.WaitGroup
+	for i, locale := range restLocales {
+		wg.Add(1)
+		go func(i int, locale string) {
+			defer wg.Done()
+			snaps[i], errs[i] = translateLocale(goaexpr.Root, locale, locales)
+		}(i, locale)
+	}
+	wg.Wait()
+	for i, locale := range restLocales {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		fs, err := generateLocale(genpkg, snaps[i].Roots(roots), locale)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
+	}
+	return files, nil
+}
 
-		fs, _ := httpcodegen.OpenAPIFiles(goaexpr.Root)
-		// Rename the files
+// translateLocale returns a copy of the design translated in the given locale.
+// The examples of the copy are generated with a new generator so that the
+// examples of the user types are generated again using the translated examples
+// and so that the random examples match the default specifications.
+func translateLocale(root *goaexpr.RootExpr, locale string, locales []string) (*snapshot, error) {
+	snap, err := newSnapshot(root)
+	if err != nil {
+		return nil, err
+	}
+	walkTranslations(snap.Root, fallbacks(locale, locales), snap.Lookup)
+	return snap, nil
+}
+
+// generateLocale runs the registered generators with the given roots holding
+// the design translated in the given locale.
+func generateLocale(genpkg string, roots []eval.Root, locale string) ([]*codegen.File, error) {
+	var files []*codegen.File
+	for _, g := range generators {
+		fs, err := g.fn(genpkg, roots, nil)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: %s generator failed for locale %q: %w", g.name, locale, err)
+		}
 		for _, file := range fs {
-			sp := strings.Split(file.Path, ".")
-			file.Path = fmt.Sprintf("%s_%s.%s", sp[0], locale, sp[1])
+			file.Path = localizedPath(file.Path, locale)
 		}
 		files = append(files, fs...)
 	}
-	// Not sure if needed, but reset messages to defaultLocale
-	walkTranslations(roots, defaultLocale)
 	return files, nil
 }
@@ -1,14 +1,24 @@
 package i18n_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"goa.design/goa/v3/codegen"
 	"goa.design/goa/v3/eval"
 	"goa.design/goa/v3/expr"
 	httpcodegen "goa.design/goa/v3/http/codegen"
+	"goa.design/plugins/v3/cors"
+	"goa.design/plugins/v3/docs"
 	"goa.design/plugins/v3/i18n"
+	"goa.design/plugins/v3/i18n/catalogs"
+	i18ndsl "goa.design/plugins/v3/i18n/dsl"
+	i18nexpr "goa.design/plugins/v3/i18n/expr"
 	"goa.design/plugins/v3/i18n/testdata"
 )
 
@@ -47,6 +57,67 @@ func TestPrepare(t *testing.T) {
 		})
 	}
 }
+func TestPrepareLocales(t *testing.T) {
+	cases := []struct {
+		Name            string
+		Env             string
+		File            string
+		Cmd             string
+		ExpectedMessage string
+	}{
+		{"dsl", "", "", "", "*title*"},
+		{"file", "", "# Generated locales\nen\nnl\n", "", "Goa"},
+		{"env", "en", "nl", "", "Goa"},
+		{"design-package", "", "", "$ goa gen goa.design/plugins/v3/i18n/testdata -o gen", "Goa"},
+	}
+	designDir := i18nexpr.Root.DesignDir
+	defer func() { i18nexpr.Root.DesignDir = designDir }()
+	args := os.Args
+	defer func() { os.Args = args }()
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			t.Setenv("GOA_I18N", c.Env)
+			httpcodegen.RunHTTPDSL(t, testdata.LocalesI18nDSL)
+			i18nexpr.Root.DesignDir = t.TempDir()
+			os.Args = args
+			if c.Cmd != "" {
+				// The locale file is looked up in the design package
+				i18nexpr.Root.DesignDir = ""
+				os.Args = append(args[:len(args):len(args)], "--cmd="+c.Cmd)
+			}
+			if c.File != "" {
+				if err := os.WriteFile(filepath.Join(i18nexpr.Root.DesignDir, i18n.LOCALEFILE), []byte(c.File), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			roots, _ := eval.Context.Roots()
+			if err := i18n.Prepare("", roots); err != nil {
+				t.Fatal(err)
+			}
+			checkExpr(roots, &expr.ServiceExpr{}, func(se interface{}) {
+				d := se.(*expr.ServiceExpr).Description
+				if d != c.ExpectedMessage {
+					t.Errorf("Description %s does not match expected value %s", d, c.ExpectedMessage)
+				}
+			})
+		})
+	}
+}
+
+func TestPrepareNoLocale(t *testing.T) {
+	t.Setenv("GOA_I18N", "")
+	httpcodegen.RunHTTPDSL(t, testdata.SimpleI18nDSL)
+	locales := i18nexpr.Root.Locales
+	defer func() { i18nexpr.Root.Locales = locales }()
+	i18nexpr.Root.Locales = nil
+
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err == nil {
+		t.Error("expected an error when no locale is defined")
+	}
+}
+
 func checkExpr(roots []eval.Root, t interface{}, cb func(se interface{})) {
 	for _, root := range roots {
 		root.WalkSets(func(es eval.ExpressionSet) error {
@@ -75,3 +146,564 @@ func TestGenerate(t *testing.T) {
 		t.Errorf("Expected to generate eight files, received %d", len(gfs))
 	}
 }
+
+func TestGenerateGenerators(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	i18n.RegisterGenerator("docs", func(_ string, _ []eval.Root, files []*codegen.File) ([]*codegen.File, error) {
+		return append(files, &codegen.File{Path: filepath.Join("gen", "v1.2", "docs.json")}), nil
+	})
+	t.Cleanup(func() { i18n.RegisterGenerator("docs", nil) })
+
+	httpcodegen.RunHTTPDSL(t, testdata.SimpleI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range gfs {
+		paths = append(paths, f.Path)
+	}
+	expected := []string{
+		filepath.Join("gen", "http", "openapi_nl.json"),
+		filepath.Join("gen", "http", "openapi_nl.yaml"),
+		filepath.Join("gen", "http", "openapi3_nl.json"),
+		filepath.Join("gen", "http", "openapi3_nl.yaml"),
+		filepath.Join("gen", "v1.2", "docs_nl.json"),
+	}
+	if !reflect.DeepEqual(paths[len(paths)-len(expected):], expected) {
+		t.Errorf("got paths %v, expected %v", paths, expected)
+	}
+}
+
+func TestGenerateDocs(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	i18n.RegisterGenerator("docs", docs.Generate)
+	t.Cleanup(func() { i18n.RegisterGenerator("docs", nil) })
+
+	httpcodegen.RunHTTPDSL(t, testdata.SimpleI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		API struct {
+			Title string `json:"title"`
+		} `json:"api"`
+		Services map[string]struct {
+			Description string `json:"description"`
+		} `json:"services"`
+	}
+	var found bool
+	for _, f := range gfs {
+		if f.Path != filepath.Join("gen", "docs_nl.json") {
+			continue
+		}
+		found = true
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !found {
+		t.Fatal("docs_nl.json not generated")
+	}
+	if doc.API.Title != "*title*" {
+		t.Errorf("got API title %q, expected %q", doc.API.Title, "*title*")
+	}
+	if d := doc.Services["SimpleOrigin"].Description; d != "*title*" {
+		t.Errorf("got service description %q, expected %q", d, "*title*")
+	}
+}
+
+func TestGenerateSnapshot(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	var checked bool
+	i18n.RegisterGenerator("check", func(_ string, roots []eval.Root, _ []*codegen.File) ([]*codegen.File, error) {
+		for _, root := range roots {
+			r, ok := root.(*expr.RootExpr)
+			if !ok {
+				continue
+			}
+			if r == expr.Root {
+				t.Error("generator given the design instead of a translated copy")
+			}
+			if d := r.Services[0].Description; d != "*title*" {
+				t.Errorf("got service description %q in the translated copy, expected %q", d, "*title*")
+			}
+			if l := r.API.Meta["openapi:extension:x-locale"]; len(l) != 1 || l[0] != "nl" {
+				t.Errorf("got x-locale %v in the translated copy, expected [nl]", l)
+			}
+			checkDesign(t)
+			checked = true
+		}
+		return nil, nil
+	})
+	t.Cleanup(func() { i18n.RegisterGenerator("check", nil) })
+
+	httpcodegen.RunHTTPDSL(t, testdata.SimpleI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i18n.Generate("", roots, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !checked {
+		t.Error("generator not run")
+	}
+	checkDesign(t)
+}
+
+// checkDesign checks that the design generated with SimpleI18nDSL is
+// translated in the default locale only.
+func checkDesign(t *testing.T) {
+	t.Helper()
+	if title := expr.Root.API.Title; title != "Goa" {
+		t.Errorf("got API title %q, expected %q", title, "Goa")
+	}
+	if d := expr.Root.Services[0].Description; d != "Goa" {
+		t.Errorf("got service description %q, expected %q", d, "Goa")
+	}
+	if l := expr.Root.API.Meta["openapi:extension:x-locale"]; len(l) != 1 || l[0] != "en" {
+		t.Errorf("got x-locale %v, expected [en]", l)
+	}
+	att := expr.AsObject(expr.Root.Services[0].Methods[0].Payload.Type).Attribute("name")
+	if len(att.UserExamples) != 1 || att.UserExamples[0].Value != "Goa" {
+		t.Errorf("got examples %v, expected a single Goa example", att.UserExamples)
+	}
+}
+
+func TestGenerateAllExpressions(t *testing.T) {
+	t.Setenv("GOA_I18N", "nl,en")
+	httpcodegen.RunHTTPDSL(t, testdata.AllI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	fs, _ := httpcodegen.OpenAPIFiles(expr.Root)
+	gfs, err := i18n.Generate("", roots, fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec string
+	for _, f := range gfs {
+		if filepath.Base(f.Path) != "openapi3_en.json" {
+			continue
+		}
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		spec = buf.String()
+	}
+	if spec == "" {
+		t.Fatal("openapi3_en.json not generated")
+	}
+	for _, m := range []string{"Goa server", "Goa scheme", "Goa docs", "Goa error", "Goa attribute", "Goa example", `"x-enum-descriptions":["Goa add","Goa sub"]`, "Goa response", "Goa error response"} {
+		if !strings.Contains(spec, m) {
+			t.Errorf("translation %q not found in openapi3_en.json", m)
+		}
+	}
+	for _, m := range []string{"*attribute*", "*example*", "*error*"} {
+		if strings.Contains(spec, m) {
+			t.Errorf("default locale translation %q found in openapi3_en.json", m)
+		}
+	}
+	if d := expr.Root.API.Servers[0].Hosts[0].Description; d != "*host*" {
+		t.Errorf("got host description %q, expected the default locale translation %q", d, "*host*")
+	}
+}
+
+func TestPrepareInvalidLocale(t *testing.T) {
+	t.Setenv("GOA_I18N", "en,not a locale")
+	httpcodegen.RunHTTPDSL(t, testdata.SimpleI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err == nil {
+		t.Error("expected an error for an invalid locale")
+	}
+}
+
+func TestGenerateFallbacks(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,de,de_AT")
+	httpcodegen.RunHTTPDSL(t, testdata.FallbackI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specs := make(map[string]string)
+	for _, f := range gfs {
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		specs[filepath.Base(f.Path)] = buf.String()
+	}
+	cases := map[string][]string{
+		"openapi3_de.json":    {`"x-locale":"de"`, "Rechner", "Der Rechendienst", `"description":"Add"`},
+		"openapi3_de_AT.json": {`"x-locale":"de-AT"`, "Rechner (AT)", "Der Rechendienst", `"description":"Add"`},
+	}
+	for name, msgs := range cases {
+		spec, ok := specs[name]
+		if !ok {
+			t.Errorf("%s not generated", name)
+			continue
+		}
+		for _, m := range msgs {
+			if !strings.Contains(spec, m) {
+				t.Errorf("%q not found in %s", m, name)
+			}
+		}
+	}
+	if x := expr.Root.API.Meta["openapi:extension:x-locale"]; !reflect.DeepEqual(x, []string{"en"}) {
+		t.Errorf("got x-locale %v after generation, expected the default locale", x)
+	}
+}
+
+func TestGenerateMeta(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.MetaI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec string
+	for _, f := range gfs {
+		if filepath.Base(f.Path) != "openapi3_nl.json" {
+			continue
+		}
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		spec = buf.String()
+	}
+	if spec == "" {
+		t.Fatal("openapi3_nl.json not generated")
+	}
+	for _, m := range []string{`"x-audience":"openbaar"`, `"summary":"Optellen"`, `"x-unit":"meter (nl)"`, `"name":"Calc","description":"Berekeningen"`, `"name":"Other","description":"Andere"`} {
+		if !strings.Contains(spec, m) {
+			t.Errorf("translation %q not found in openapi3_nl.json", m)
+		}
+	}
+}
+
+func TestGenerateLocalizedFiles(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.LocalizedFilesI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, httpcodegen.ServerFiles("", expr.Root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header bytes.Buffer
+	var code string
+	for _, f := range gfs {
+		if filepath.Base(f.Path) == "server.go" {
+			if err := f.SectionTemplates[0].Write(&header); err != nil {
+				t.Fatal(err)
+			}
+			code = codegen.SectionsCode(t, f.SectionTemplates[1:])
+		}
+	}
+	if !strings.Contains(header.String(), `goai18n "goa.design/plugins/v3/i18n"`) {
+		t.Errorf("plugin package not imported in server.go:\n%s", header.String())
+	}
+	for _, c := range []string{
+		`GenHTTPOpenapi3JSON: goai18n.LocalizedFiles(http.FileServer(fileSystemGenHTTPOpenapi3JSON), "en", "nl"),`,
+		`PublicIndexHTML:     http.FileServer(fileSystemPublicIndexHTML),`,
+	} {
+		if !strings.Contains(code, c) {
+			t.Errorf("%q not found in server.go:\n%s", c, code)
+		}
+	}
+}
+
+func TestGenerateLocalizedFilesUnsupported(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.LocalizedFilesI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	fs := httpcodegen.ServerFiles("", expr.Root)
+	for _, f := range fs {
+		for _, s := range f.Section("server-init") {
+			s.Source = strings.ReplaceAll(s.Source, "{{ .VarName }}: ", "{{ .VarName }}:")
+		}
+	}
+	_, err := i18n.Generate("", roots, fs)
+	if err == nil || !strings.Contains(err.Error(), "goa version is not supported") {
+		t.Errorf("got error %v, expected unsupported goa version error", err)
+	}
+}
+
+func TestGenerateLocalizedFilesCORS(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Plugins  []codegen.GenerateFunc
+		Expected []string
+	}{
+		{"cors-i18n", []codegen.GenerateFunc{cors.Generate, i18n.Generate}, []string{
+			`GenHTTPOpenapi3JSON: goai18n.LocalizedFiles(HandleLocalizedFilesCORSFilesOrigin(http.FileServer(fileSystemGenHTTPOpenapi3JSON), corsOpts...), "en", "nl"),`,
+			`PublicIndexHTML:     HandleLocalizedFilesCORSFilesOrigin(http.FileServer(fileSystemPublicIndexHTML), corsOpts...),`,
+		}},
+		{"i18n-cors", []codegen.GenerateFunc{i18n.Generate, cors.Generate}, []string{
+			`GenHTTPOpenapi3JSON: HandleLocalizedFilesCORSFilesOrigin(goai18n.LocalizedFiles(http.FileServer(fileSystemGenHTTPOpenapi3JSON), "en", "nl"), corsOpts...),`,
+			`PublicIndexHTML:     HandleLocalizedFilesCORSFilesOrigin(http.FileServer(fileSystemPublicIndexHTML), corsOpts...),`,
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			resetRoot(t)
+			t.Setenv("GOA_I18N", "en,nl")
+			httpcodegen.RunHTTPDSL(t, testdata.LocalizedFilesCORSDSL)
+			roots, _ := eval.Context.Roots()
+			if err := i18n.Prepare("", roots); err != nil {
+				t.Fatal(err)
+			}
+			gfs := httpcodegen.ServerFiles("", expr.Root)
+			for _, plugin := range c.Plugins {
+				var err error
+				gfs, err = plugin("", roots, gfs)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			var code string
+			for _, f := range gfs {
+				if filepath.Base(f.Path) == "server.go" {
+					code = codegen.SectionsCode(t, f.Section("server-init"))
+				}
+			}
+			for _, e := range c.Expected {
+				if !strings.Contains(code, e) {
+					t.Errorf("%q not found in server.go:\n%s", e, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateReport(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.ReportI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	// Expression left over by a previous run, not part of the design
+	leftover := &expr.ServiceExpr{Name: "Leftover"}
+	i18nexpr.Root.Description[leftover] = &i18nexpr.I18nExpr{
+		Name:   "description",
+		Trans:  []i18nexpr.Translateable{func(string) string { return "" }},
+		Parent: leftover,
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report map[string][]*i18n.ReportEntry
+	for _, f := range gfs {
+		if f.Path != filepath.Join("gen", "i18n", "report.json") {
+			continue
+		}
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := map[string][]*i18n.ReportEntry{
+		"en": {},
+		"nl": {
+			{ID: "service.ReportOrigin.description", Default: "Goa", Translation: "*title*", Status: i18n.StatusMissing},
+			{ID: "service.ReportOrigin.method.ReportOriginMethod.description", Default: "Same", Translation: "Same", Status: i18n.StatusIdentical},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		got, _ := json.Marshal(report)
+		t.Errorf("got report %s", got)
+	}
+}
+
+func TestGenerateStrict(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Locales string
+		Error   bool
+	}{
+		{"translated", "en", false},
+		{"missing", "en,nl", true},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			resetRoot(t)
+			t.Setenv("GOA_I18N", c.Locales)
+			httpcodegen.RunHTTPDSL(t, testdata.StrictI18nDSL)
+			roots, _ := eval.Context.Roots()
+			if err := i18n.Prepare("", roots); err != nil {
+				t.Fatal(err)
+			}
+			_, err := i18n.Generate("", roots, nil)
+			if c.Error && err == nil {
+				t.Error("expected an error for the missing translation")
+			}
+			if !c.Error && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+// resetRoot resets the i18n root expression for the duration of the test.
+func resetRoot(t *testing.T) {
+	root := *i18nexpr.Root
+	*i18nexpr.Root = i18nexpr.RootExpr{
+		Description: map[eval.Expression]*i18nexpr.I18nExpr{},
+		Example:     map[eval.Expression]*i18nexpr.I18nExpr{},
+		Title:       map[eval.Expression]*i18nexpr.I18nExpr{},
+		Enum:        map[eval.Expression][]*i18nexpr.I18nExpr{},
+		Meta:        map[eval.Expression][]*i18nexpr.I18nExpr{},
+		Messages:    map[string]*i18nexpr.I18nExpr{},
+	}
+	t.Cleanup(func() { *i18nexpr.Root = root })
+}
+
+func TestGenerateCatalog(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.CatalogI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var code string
+	for _, f := range gfs {
+		if f.Path != filepath.Join("gen", "i18n", "catalog.go") {
+			continue
+		}
+		code = codegen.SectionCode(t, f.SectionTemplates[1])
+	}
+	if code != testdata.CatalogCode {
+		t.Errorf("invalid code, got:\n%s\nexpected:\n%s", code, testdata.CatalogCode)
+	}
+}
+
+func TestGenerateExtract(t *testing.T) {
+	resetRoot(t)
+	t.Setenv("GOA_I18N", "en,nl")
+	httpcodegen.RunHTTPDSL(t, testdata.ExtractI18nDSL)
+	roots, _ := eval.Context.Roots()
+	if err := i18n.Prepare("", roots); err != nil {
+		t.Fatal(err)
+	}
+	gfs, err := i18n.Generate("", roots, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	var paths []string
+	for _, f := range gfs {
+		if filepath.Dir(f.Path) != filepath.Join("gen", "i18n") || filepath.Ext(f.Path) == ".go" {
+			continue
+		}
+		var buf bytes.Buffer
+		for _, s := range f.SectionTemplates {
+			if err := s.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		path := filepath.Join(dir, filepath.Base(f.Path))
+		if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) != 6 {
+		t.Fatalf("got %d catalogs, expected 6: %v", len(paths), paths)
+	}
+
+	r, err := os.Open(filepath.Join(dir, "nl.po"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	nl, err := catalogs.Read(r, catalogs.FormatPO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &catalogs.File{
+		Locale:       "nl",
+		SourceLocale: "en",
+		Entries: []*catalogs.Entry{
+			{ID: "service.ExtractOrigin.description", Description: `I18N description of service "ExtractOrigin"`, Source: "Goa"},
+			{ID: "service.ExtractOrigin.method.ExtractOriginMethod.description", Description: `I18N description of service "ExtractOrigin" method "ExtractOriginMethod"`, Source: "Add", Target: "Optellen"},
+			{ID: "service.ExtractOrigin.method.ExtractOriginMethod.payload.a.description", Description: "I18N description of attribute", Source: "Goa attribute"},
+		},
+	}
+	if !reflect.DeepEqual(nl, expected) {
+		got, _ := json.Marshal(nl)
+		t.Errorf("got catalog %s", got)
+	}
+
+	for _, path := range paths {
+		c, err := i18ndsl.LoadCatalogs(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		locale := catalogs.LocaleOf(path)
+		tr := c.T("service.ExtractOrigin.method.ExtractOriginMethod.description")
+		if got, want := tr(locale), map[string]string{"en": "Add", "nl": "Optellen"}[locale]; got != want {
+			t.Errorf("got %q for %s, expected %q", got, path, want)
+		}
+		if got := c.T("service.ExtractOrigin.description")(locale); locale == "nl" && got != "" {
+			t.Errorf("got %q for the missing translation of %s, expected none", got, path)
+		}
+	}
+}
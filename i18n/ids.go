@@ -0,0 +1,179 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+
+	"goa.design/goa/v3/eval"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/i18n/expr"
+)
+
+// translation is a translated text identified by a stable ID.
+type translation struct {
+	// ID is derived from the path of the translated expression.
+	ID string
+	// I18nExpr is the expression defining the translation.
+	I18nExpr *expr.I18nExpr
+	// Index is the index of the text in the translations of I18nExpr.
+	Index int
+}
+
+// translations returns the translated texts of the design sorted by ID. The
+// IDs are derived from the path of the translated expressions in the design,
+// for example "service.calc.method.add.payload.a.description".
+func translations(root *goaexpr.RootExpr) []*translation {
+	paths := expressionPaths(root)
+	var ts []*translation
+	expr.Root.WalkSets(func(s eval.ExpressionSet) error {
+		for _, e := range s {
+			i18nExpr := e.(*expr.I18nExpr)
+			path, ok := paths[i18nExpr.Parent]
+			if !ok {
+				// Expressions that are not part of the design, e.g.
+				// defined in a previous run.
+				continue
+			}
+			id := path + "." + i18nExpr.Name
+			if i18nExpr.Value != nil {
+				id += "." + fmt.Sprint(i18nExpr.Value)
+			}
+			if i18nExpr.Name == "example" && len(i18nExpr.Trans) > 1 {
+				ts = append(ts, &translation{ID: id + ".summary", I18nExpr: i18nExpr})
+				ts = append(ts, &translation{ID: id, I18nExpr: i18nExpr, Index: 1})
+				continue
+			}
+			ts = append(ts, &translation{ID: id, I18nExpr: i18nExpr})
+		}
+		return nil
+	})
+	sort.Slice(ts, func(i, j int) bool { return ts[i].ID < ts[j].ID })
+	return ts
+}
+
+// expressionPaths returns the paths of the expressions of the design that may
+// be translated.
+func expressionPaths(root *goaexpr.RootExpr) map[eval.Expression]string {
+	paths := make(map[eval.Expression]string)
+	add := func(e eval.Expression, path string) bool {
+		if e == nil {
+			return false
+		}
+		if _, ok := paths[e]; ok {
+			return false
+		}
+		paths[e] = path
+		return true
+	}
+	types := make(map[string]bool)
+	for _, t := range root.Types {
+		types[t.Name()] = true
+	}
+	for _, t := range root.ResultTypes {
+		types[t.Name()] = true
+	}
+	var addAtt func(*goaexpr.AttributeExpr, string)
+	addAtt = func(att *goaexpr.AttributeExpr, path string) {
+		if att == nil || !add(att, path) {
+			return
+		}
+		if att.Docs != nil {
+			add(att.Docs, path+".docs")
+		}
+		switch dt := att.Type.(type) {
+		case goaexpr.UserType:
+			if !types[dt.Name()] {
+				addAtt(dt.Attribute(), path)
+			}
+		case *goaexpr.Object:
+			for _, nat := range *dt {
+				addAtt(nat.Attribute, path+"."+nat.Name)
+			}
+		case *goaexpr.Array:
+			addAtt(dt.ElemType, path+".elem")
+		case *goaexpr.Map:
+			addAtt(dt.KeyType, path+".key")
+			addAtt(dt.ElemType, path+".elem")
+		case *goaexpr.Union:
+			for _, nat := range dt.Values {
+				addAtt(nat.Attribute, path+"."+nat.Name)
+			}
+		}
+	}
+	addErrors := func(errs []*goaexpr.ErrorExpr, path string) {
+		for _, e := range errs {
+			addAtt(e.AttributeExpr, path+".error."+e.Name)
+		}
+	}
+
+	if root.API != nil {
+		add(root.API, "api")
+		if root.API.Docs != nil {
+			add(root.API.Docs, "api.docs")
+		}
+		for _, s := range root.API.Servers {
+			spath := "api.server." + s.Name
+			add(s, spath)
+			for _, h := range s.Hosts {
+				add(h, spath+".host."+h.Name)
+			}
+		}
+	}
+	for _, s := range root.Schemes {
+		add(s, "scheme."+s.SchemeName)
+	}
+	addErrors(root.Errors, "api")
+	for _, svc := range root.Services {
+		spath := "service." + svc.Name
+		add(svc, spath)
+		if svc.Docs != nil {
+			add(svc.Docs, spath+".docs")
+		}
+		addErrors(svc.Errors, spath)
+		for _, m := range svc.Methods {
+			mpath := spath + ".method." + m.Name
+			add(m, mpath)
+			if m.Docs != nil {
+				add(m.Docs, mpath+".docs")
+			}
+			addAtt(m.Payload, mpath+".payload")
+			addAtt(m.StreamingPayload, mpath+".streaming_payload")
+			addAtt(m.Result, mpath+".result")
+			addErrors(m.Errors, mpath)
+		}
+	}
+	for _, t := range root.Types {
+		addAtt(t.Attribute(), "type."+t.Name())
+	}
+	for _, t := range root.ResultTypes {
+		add(t, "type."+t.Name())
+		addAtt(t.Attribute(), "type."+t.Name())
+	}
+	if root.API == nil || root.API.HTTP == nil {
+		return paths
+	}
+	for _, svc := range root.API.HTTP.Services {
+		spath := "service." + svc.Name()
+		add(svc, spath+".http")
+		for _, e := range svc.HTTPErrors {
+			add(e.Response, spath+".http.error."+e.Name)
+		}
+		for _, fs := range svc.FileServers {
+			add(fs, spath+".files."+fs.RequestPaths[0])
+		}
+		for _, e := range svc.HTTPEndpoints {
+			epath := spath + ".method." + e.Name() + ".http"
+			add(e, epath)
+			for i, r := range e.Routes {
+				add(r, fmt.Sprintf("%s.route.%d", epath, i))
+			}
+			for _, r := range e.Responses {
+				add(r, fmt.Sprintf("%s.response.%d", epath, r.StatusCode))
+			}
+			for _, herr := range e.HTTPErrors {
+				add(herr.Response, epath+".error."+herr.Name)
+			}
+		}
+	}
+	return paths
+}
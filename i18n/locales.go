@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	goaexpr "goa.design/goa/v3/expr"
+	"golang.org/x/text/language"
+)
+
+// checkLocales returns an error if one of the locales is not a well-formed
+// BCP 47 language tag. Underscores are accepted as subtag separators, e.g.
+// de_AT.
+func checkLocales(locales []string) error {
+	for _, locale := range locales {
+		if _, err := language.Parse(locale); err != nil {
+			return fmt.Errorf("invalid locale %q: %s", locale, err)
+		}
+	}
+	return nil
+}
+
+// fallbacks returns the fallback chain of the locale given the configured
+// locales: the locale, its parent locales and the default locale. The parent
+// locales are obtained by removing the region and the script of the locale
+// and use the subtag separator of the locale, e.g. the fallback chain of
+// de_AT is de_AT, de, en if en is the default locale.
+func fallbacks(locale string, locales []string) []string {
+	chain := []string{locale}
+	add := func(l string) {
+		for _, c := range chain {
+			if c == l {
+				return
+			}
+		}
+		chain = append(chain, l)
+	}
+	if tag, err := language.Parse(locale); err == nil {
+		sep := "-"
+		if strings.Contains(locale, "_") {
+			sep = "_"
+		}
+		base, script, region := tag.Raw()
+		if script != (language.Script{}) && region != (language.Region{}) {
+			add(base.String() + sep + script.String())
+		}
+		add(base.String())
+	}
+	add(locales[0])
+	return chain
+}
+
+// tagOf returns the canonical BCP 47 language tag of the locale, e.g. de-AT
+// for de_AT.
+func tagOf(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	return tag.String()
+}
+
+// setLocaleExtension sets the x-locale extension of the OpenAPI info object of
+// the API to the language tag of the locale so that the specifications of each locale
+// can be told apart.
+func setLocaleExtension(api *goaexpr.APIExpr, locale string) {
+	if api == nil {
+		return
+	}
+	if api.Meta == nil {
+		api.Meta = goaexpr.MetaExpr{}
+	}
+	api.Meta["openapi:extension:x-locale"] = []string{tagOf(locale)}
+}
+
+// parentFallbacks returns the fallback chain of the locale without the default
+// locale unless the locale is the default locale. The translations missing in
+// all the locales of the chain are reported as missing.
+func parentFallbacks(locale string, locales []string) []string {
+	chain := fallbacks(locale, locales)
+	if locale == locales[0] {
+		return chain[:1]
+	}
+	return chain[:len(chain)-1]
+}
@@ -0,0 +1,141 @@
+package i18n
+
+import (
+	"path/filepath"
+	"sort"
+
+	"goa.design/goa/v3/codegen"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/i18n/expr"
+)
+
+type (
+	// catalogData contains the data necessary to generate the runtime
+	// catalog of the translated error messages.
+	catalogData struct {
+		// Locales lists the locales, the first locale is the default
+		// locale.
+		Locales []string
+		// Messages lists the messages of each locale.
+		Messages []*localeMessagesData
+	}
+
+	// localeMessagesData lists the error messages of a locale.
+	localeMessagesData struct {
+		// Locale is the locale of the messages.
+		Locale string
+		// Messages lists the messages sorted by error name.
+		Messages []*messageData
+	}
+
+	// messageData describes a translated error message.
+	messageData struct {
+		// Name is the error name.
+		Name string
+		// Message is the translated message.
+		Message string
+	}
+)
+
+// catalogFile returns the file defining the runtime catalog of the error
+// messages translated with the ErrorMessage DSL and of the translated
+// descriptions of the errors defined in the design. It returns nil if the
+// design does not define HTTP services or translated error messages.
+func catalogFile(root *goaexpr.RootExpr, locales []string) *codegen.File {
+	if root.API == nil || root.API.HTTP == nil || len(root.API.HTTP.Services) == 0 {
+		return nil
+	}
+	data := buildCatalogData(root, locales)
+	empty := true
+	for _, lm := range data.Messages {
+		if len(lm.Messages) > 0 {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		return nil
+	}
+	sections := []*codegen.SectionTemplate{
+		codegen.Header("i18n message catalog", "i18n", []*codegen.ImportSpec{
+			{Name: "goai18n", Path: "goa.design/plugins/v3/i18n"},
+		}),
+		{
+			Name:    "i18n-catalog",
+			Source:  catalogT,
+			Data:    data,
+			FuncMap: codegen.TemplateFuncs(),
+		},
+	}
+	return &codegen.File{
+		Path:             filepath.Join(codegen.Gendir, "i18n", "catalog.go"),
+		SectionTemplates: sections,
+	}
+}
+
+// buildCatalogData builds the messages of each locale. The messages defined
+// with ErrorMessage take precedence over the error descriptions, the first
+// error defined with a given name (API, then services and methods) provides
+// the description. Missing translations are not listed so that the catalog
+// falls back to the default locale at runtime.
+func buildCatalogData(root *goaexpr.RootExpr, locales []string) *catalogData {
+	errors := make(map[string]*expr.I18nExpr)
+	addErrors := func(errs []*goaexpr.ErrorExpr) {
+		for _, e := range errs {
+			if _, ok := errors[e.Name]; ok {
+				continue
+			}
+			if i18nExpr, ok := expr.Root.Description[e.AttributeExpr]; ok {
+				errors[e.Name] = i18nExpr
+			}
+		}
+	}
+	addErrors(root.Errors)
+	for _, svc := range root.Services {
+		addErrors(svc.Errors)
+		for _, m := range svc.Methods {
+			addErrors(m.Errors)
+		}
+	}
+	for name, i18nExpr := range expr.Root.Messages {
+		errors[name] = i18nExpr
+	}
+	names := make([]string, 0, len(errors))
+	for name := range errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := &catalogData{Locales: locales}
+	for _, locale := range locales {
+		lm := &localeMessagesData{Locale: locale}
+		for _, name := range names {
+			msg := errors[name].Translate(parentFallbacks(locale, locales))[0]
+			if expr.Root.IsMissing(msg) {
+				continue
+			}
+			lm.Messages = append(lm.Messages, &messageData{Name: name, Message: msg})
+		}
+		data.Messages = append(data.Messages, lm)
+	}
+	return data
+}
+
+// Data: catalogData
+const catalogT = `// Catalog lists the error messages translated in the design. Its error
+// formatter can be given to the generated HTTP servers, the Middleware and
+// ResponseEncoder methods translate the error responses in the locale requested
+// with the Accept-Language header.
+var Catalog = &goai18n.Catalog{
+	Locales: []string{ {{ range .Locales }}{{ printf "%q" . }}, {{ end }} },
+	Messages: map[string]map[string]string{
+	{{- range .Messages }}
+		{{ printf "%q" .Locale }}: {
+		{{- range .Messages }}
+			{{ printf "%q" .Name }}: {{ printf "%q" .Message }},
+		{{- end }}
+		},
+	{{- end }}
+	},
+}
+`
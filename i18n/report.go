@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"goa.design/goa/v3/codegen"
+	goaexpr "goa.design/goa/v3/expr"
+	"goa.design/plugins/v3/i18n/expr"
+)
+
+const (
+	// StatusMissing is the status of the translations that are empty or that
+	// match the placeholder defined with the Placeholder DSL.
+	StatusMissing = "missing"
+	// StatusIdentical is the status of the translations that are identical
+	// to the translation of the default locale.
+	StatusIdentical = "identical"
+)
+
+// ReportEntry describes a translation listed in the report generated with the
+// Report DSL.
+type ReportEntry struct {
+	// ID identifies the translation, it is derived from the path of the
+	// translated expression in the design as the IDs of the catalogs.
+	ID string `json:"id"`
+	// Default is the translation in the default locale.
+	Default string `json:"default"`
+	// Translation is the translation in the reported locale.
+	Translation string `json:"translation"`
+	// Status is either StatusMissing or StatusIdentical.
+	Status string `json:"status"`
+}
+
+// buildReport returns the missing translations and the translations that are
+// identical to the default locale of the given design indexed by locale and
+// sorted by ID. The first locale is the default locale. A translation is
+// missing if it is missing in the locale and in its parent locales.
+func buildReport(root *goaexpr.RootExpr, locales []string) map[string][]*ReportEntry {
+	ts := translations(root)
+	report := make(map[string][]*ReportEntry, len(locales))
+	for i, locale := range locales {
+		entries := []*ReportEntry{}
+		for _, t := range ts {
+			def := t.I18nExpr.Messages(locales[0])[t.Index]
+			msg := t.I18nExpr.Translate(parentFallbacks(locale, locales))[t.Index]
+			entry := &ReportEntry{ID: t.ID, Default: def, Translation: msg}
+			switch {
+			case expr.Root.IsMissing(msg):
+				entry.Status = StatusMissing
+			case i > 0 && msg == def:
+				entry.Status = StatusIdentical
+			default:
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		report[locale] = entries
+	}
+	return report
+}
+
+// checkStrict returns an error listing the missing translations of the report.
+func checkStrict(locales []string, report map[string][]*ReportEntry) error {
+	var missing []string
+	for _, locale := range locales {
+		for _, e := range report[locale] {
+			if e.Status == StatusMissing {
+				missing = append(missing, fmt.Sprintf("%s (%s): %q", e.ID, locale, e.Translation))
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("missing translations:\n%s", strings.Join(missing, "\n"))
+}
+
+// reportFile returns the file listing the reported translations.
+func reportFile(report map[string][]*ReportEntry) (*codegen.File, error) {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("i18n: failed to encode report: %w", err)
+	}
+	return &codegen.File{
+		Path: filepath.Join(codegen.Gendir, "i18n", "report.json"),
+		SectionTemplates: []*codegen.SectionTemplate{{
+			Name:   "i18n-report",
+			Source: "{{ . }}\n",
+			Data:   string(b),
+		}},
+	}, nil
+}
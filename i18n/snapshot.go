@@ -0,0 +1,197 @@
+package i18n
+
+import (
+	"fmt"
+	"reflect"
+
+	"goa.design/goa/v3/eval"
+	goaexpr "goa.design/goa/v3/expr"
+)
+
+var (
+	// exprPkgPath is the import path of the package of the Goa expressions.
+	exprPkgPath = reflect.TypeOf(goaexpr.RootExpr{}).PkgPath()
+
+	// dslFuncType is the type of the DSL functions of the expressions which
+	// are shared with the design.
+	dslFuncType = reflect.TypeOf(eval.DSLFunc(nil))
+
+	// skippedFields lists the unexported fields of the Goa expressions that
+	// are not copied. The generator of the examples of the API is created
+	// again by APIExpr.Random so that the examples are generated from the
+	// translated examples. The flags only prevent finalizing and preparing
+	// the expressions twice, which the generators do not do.
+	skippedFields = map[string]struct{}{
+		"APIExpr.random":            {},
+		"AttributeExpr.finalized":   {},
+		"HTTPEndpointExpr.prepared": {},
+	}
+)
+
+type (
+	// snapshot is a deep copy of the Goa design that can be translated
+	// without modifying the design read by the Goa generators and the other
+	// plugins.
+	snapshot struct {
+		// Root is the copy of the design root expression.
+		Root *goaexpr.RootExpr
+		// copies maps the pointers and maps of the design to their copies.
+		copies map[copyKey]reflect.Value
+		// err is the first error encountered when copying the design.
+		err error
+	}
+
+	// copyKey identifies a pointer or map of the design.
+	copyKey struct {
+		ptr uintptr
+		typ reflect.Type
+	}
+)
+
+// newSnapshot returns a deep copy of the given design root expression. The
+// exported fields of the expressions defined in the Goa expr package are
+// copied, the other pointers are shared with the design. The built-in types
+// and result types such as Empty and ErrorResult are shared as Goa compares
+// them by identity. It returns an error if the design holds a value that
+// cannot be copied, e.g. an unexported field added by a newer Goa version.
+func newSnapshot(root *goaexpr.RootExpr) (*snapshot, error) {
+	s := &snapshot{copies: make(map[copyKey]reflect.Value)}
+	for _, e := range []interface{}{goaexpr.Empty, goaexpr.ErrorResult, goaexpr.ErrorResult.UserTypeExpr} {
+		v := reflect.ValueOf(e)
+		s.copies[copyKey{v.Pointer(), v.Type()}] = v
+	}
+	s.Root = s.copy(reflect.ValueOf(root)).Interface().(*goaexpr.RootExpr)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s, nil
+}
+
+// Lookup returns the copy of the given expression of the design, nil if the
+// expression is not part of the design.
+func (s *snapshot) Lookup(e eval.Expression) eval.Expression {
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Ptr {
+		return nil
+	}
+	c, ok := s.copies[copyKey{v.Pointer(), v.Type()}]
+	if !ok {
+		return nil
+	}
+	return c.Interface().(eval.Expression)
+}
+
+// Roots returns the given roots with the design root replaced by its copy.
+func (s *snapshot) Roots(roots []eval.Root) []eval.Root {
+	rs := make([]eval.Root, len(roots))
+	for i, r := range roots {
+		if _, ok := r.(*goaexpr.RootExpr); ok {
+			r = s.Root
+		}
+		rs[i] = r
+	}
+	return rs
+}
+
+// copy returns a deep copy of v. The copy is v if s.err is set.
+func (s *snapshot) copy(v reflect.Value) reflect.Value {
+	if s.err != nil {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || v.Type().Elem().PkgPath() != exprPkgPath {
+			return v
+		}
+		key := copyKey{v.Pointer(), v.Type()}
+		if c, ok := s.copies[key]; ok {
+			return c
+		}
+		if ma, ok := v.Interface().(*goaexpr.MappedAttributeExpr); ok {
+			return s.copyMapped(key, ma)
+		}
+		c := reflect.New(v.Type().Elem())
+		s.copies[key] = c
+		c.Elem().Set(s.copy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(s.copy(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		if v.Type().PkgPath() != exprPkgPath {
+			c.Set(v)
+			return c
+		}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				if _, ok := skippedFields[v.Type().Name()+"."+f.Name]; !ok {
+					s.err = fmt.Errorf("i18n: cannot copy the unexported field %s of %s, the goa version is not supported", f.Name, v.Type())
+				}
+				continue
+			}
+			c.Field(i).Set(s.copy(v.Field(i)))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(s.copy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(s.copy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		key := copyKey{v.Pointer(), v.Type()}
+		if c, ok := s.copies[key]; ok {
+			return c
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		s.copies[key] = c
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(s.copy(iter.Key()), s.copy(iter.Value()))
+		}
+		return c
+	case reflect.Func:
+		if v.Type() != dslFuncType {
+			s.err = fmt.Errorf("i18n: cannot copy the function of type %s of the design", v.Type())
+		}
+		return v
+	case reflect.Chan, reflect.UnsafePointer:
+		s.err = fmt.Errorf("i18n: cannot copy the value of type %s of the design", v.Type())
+		return v
+	default:
+		return v
+	}
+}
+
+// copyMapped returns a deep copy of the given mapped attribute. The name
+// mappings are unexported and copied with DupMappedAtt.
+func (s *snapshot) copyMapped(key copyKey, ma *goaexpr.MappedAttributeExpr) reflect.Value {
+	c := &goaexpr.MappedAttributeExpr{}
+	if ma.AttributeExpr != nil {
+		c = goaexpr.DupMappedAtt(ma)
+	}
+	cv := reflect.ValueOf(c)
+	s.copies[key] = cv
+	if ma.AttributeExpr != nil {
+		c.AttributeExpr = s.copy(reflect.ValueOf(ma.AttributeExpr)).Interface().(*goaexpr.AttributeExpr)
+	}
+	return cv
+}
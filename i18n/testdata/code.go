@@ -0,0 +1,20 @@
+package testdata
+
+var CatalogCode = `// Catalog lists the error messages translated in the design. Its error
+// formatter can be given to the generated HTTP servers, the Middleware and
+// ResponseEncoder methods translate the error responses in the locale requested
+// with the Accept-Language header.
+var Catalog = &goai18n.Catalog{
+	Locales: []string{"en", "nl"},
+	Messages: map[string]map[string]string{
+		"en": {
+			"missing_field": "{field} is missing",
+			"not_found":     "Goa error",
+		},
+		"nl": {
+			"missing_field": "*missing*",
+			"not_found":     "*error*",
+		},
+	},
+}
+`
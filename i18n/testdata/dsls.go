@@ -2,6 +2,7 @@ package testdata
 
 import (
 	. "goa.design/goa/v3/dsl"
+	cors "goa.design/plugins/v3/cors/dsl"
 	i18n "goa.design/plugins/v3/i18n/dsl"
 )
 
@@ -11,13 +12,236 @@ var SimpleI18nDSL = func() {
 	})
 	Service("SimpleOrigin", func() {
 		i18n.Description(M("title"))
-		i18n.Example(M("title"))
 
 		Method("SimpleOriginMethod", func() {
+			Payload(func() {
+				Attribute("name", String, func() {
+					i18n.Example(M("title"))
+				})
+			})
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+
+}
+
+var LocalesI18nDSL = func() {
+	API("calc", func() {
+		i18n.Locales("nl", "en")
+	})
+	Service("SimpleOrigin", func() {
+		i18n.Description(M("title"))
+
+		Method("SimpleOriginMethod", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var AllI18nDSL = func() {
+	API("calc", func() {
+		i18n.Title(M("title"))
+		Server("calc", func() {
+			i18n.Description(M("server"))
+			Host("dev", func() {
+				i18n.Description(M("host"))
+				URI("http://localhost:80")
+			})
+		})
+	})
+	var BasicAuth = BasicAuthSecurity("basic", func() {
+		i18n.Description(M("scheme"))
+	})
+	Service("AllOrigin", func() {
+		Error("not_found", func() {
+			i18n.Description(M("error"))
+		})
+
+		Method("AllOriginMethod", func() {
+			Docs(func() {
+				i18n.Description(M("docs"))
+				URL("https://goa.design")
+			})
+			Security(BasicAuth)
+			Payload(func() {
+				Username("user", String)
+				Password("pass", String)
+				Attribute("op", String, func() {
+					i18n.Description(M("attribute"))
+					i18n.Example(M("example"))
+					Enum("add", "sub")
+					i18n.EnumDescription("add", M("add"))
+					i18n.EnumDescription("sub", M("sub"))
+				})
+			})
+			HTTP(func() {
+				POST("/")
+				Response(StatusOK, func() {
+					i18n.Description(M("response"))
+				})
+				Response("not_found", StatusNotFound, func() {
+					i18n.Description(M("error-response"))
+				})
+			})
+		})
+	})
+}
+
+var ReportI18nDSL = func() {
+	API("calc", func() {
+		i18n.Report()
+		i18n.Placeholder(`^\*.*\*$`)
+	})
+	Service("ReportOrigin", func() {
+		i18n.Description(M("title"))
+
+		Method("ReportOriginMethod", func() {
+			i18n.Description(func(string) string { return "Same" })
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var StrictI18nDSL = func() {
+	API("calc", func() {
+		i18n.Strict()
+		i18n.Placeholder(`^\*.*\*$`)
+	})
+	Service("StrictOrigin", func() {
+		i18n.Description(M("title"))
+
+		Method("StrictOriginMethod", func() {
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var CatalogI18nDSL = func() {
+	API("calc", func() {
+		i18n.ErrorMessage("missing_field", M("missing"))
+		i18n.ErrorMessage("invalid_range", func(string) string { return "" })
+	})
+	Service("CatalogOrigin", func() {
+		Error("not_found", func() {
+			i18n.Description(M("error"))
+		})
+
+		Method("CatalogOriginMethod", func() {
 			HTTP(func() {
 				GET("/")
 			})
 		})
 	})
+}
+
+var ExtractI18nDSL = func() {
+	API("calc", func() {
+		i18n.Extract("po", "xliff", "json")
+		i18n.Placeholder(`^\*.*\*$`)
+	})
+	Service("ExtractOrigin", func() {
+		i18n.Description(M("title"))
+
+		Method("ExtractOriginMethod", func() {
+			i18n.Description(func(locale string) string {
+				if locale == "nl" {
+					return "Optellen"
+				}
+				return "Add"
+			})
+			Payload(func() {
+				Attribute("a", Int, func() {
+					i18n.Description(M("attribute"))
+				})
+			})
+			HTTP(func() {
+				POST("/")
+			})
+		})
+	})
+}
 
+var FallbackI18nDSL = func() {
+	API("calc", func() {
+		i18n.Title(func(locale string) string {
+			return map[string]string{"en": "Calc", "de": "Rechner", "de_AT": "Rechner (AT)"}[locale]
+		})
+	})
+	Service("FallbackOrigin", func() {
+		i18n.Description(func(locale string) string {
+			return map[string]string{"en": "The calc service", "de": "Der Rechendienst"}[locale]
+		})
+
+		Method("FallbackOriginMethod", func() {
+			i18n.Description(func(locale string) string {
+				return map[string]string{"en": "Add"}[locale]
+			})
+			HTTP(func() {
+				GET("/")
+			})
+		})
+	})
+}
+
+var MetaI18nDSL = func() {
+	API("calc", func() {
+		Meta("openapi:extension:x-audience", "public")
+		i18n.Meta("openapi:extension:x-audience", func(locale string) string {
+			return map[string]string{"en": "public", "nl": "openbaar"}[locale]
+		})
+	})
+	Service("MetaOrigin", func() {
+		Method("MetaOriginMethod", func() {
+			Meta("openapi:summary", "Add")
+			i18n.Meta("openapi:summary", func(locale string) string {
+				return map[string]string{"en": "Add", "nl": "Optellen"}[locale]
+			})
+			Payload(func() {
+				Attribute("a", Int, func() {
+					i18n.Meta("openapi:extension:x-unit", func(locale string) string {
+						return map[string]string{"en": "meter", "nl": "meter (nl)"}[locale]
+					})
+				})
+			})
+			HTTP(func() {
+				POST("/")
+			})
+		})
+		HTTP(func() {
+			Meta("swagger:tag:Calc")
+			i18n.TagDescription("Calc", func(locale string) string {
+				return map[string]string{"en": "Calculations", "nl": "Berekeningen"}[locale]
+			})
+			i18n.TagDescription("Other", func(locale string) string {
+				return map[string]string{"en": "Other", "nl": "Andere"}[locale]
+			})
+		})
+	})
+}
+
+var LocalizedFilesI18nDSL = func() {
+	Service("LocalizedFilesOrigin", func() {
+		Files("/openapi3.json", "./gen/http/openapi3.json", func() {
+			i18n.ServeLocalized()
+		})
+		Files("/index.html", "./public/index.html")
+	})
+}
+
+var LocalizedFilesCORSDSL = func() {
+	Service("LocalizedFilesCORS", func() {
+		cors.Origin("http://localhost")
+		Files("/openapi3.json", "./gen/http/openapi3.json", func() {
+			i18n.ServeLocalized()
+		})
+		Files("/index.html", "./public/index.html")
+	})
 }
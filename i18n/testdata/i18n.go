@@ -10,7 +10,19 @@ import (
 var messages map[string]map[string]string
 var _ = json.Unmarshal([]byte(`{
 	"en": {
-		"title": "Goa"
+		"title": "Goa",
+		"server": "Goa server",
+		"host": "Goa host",
+		"scheme": "Goa scheme",
+		"docs": "Goa docs",
+		"error": "Goa error",
+		"attribute": "Goa attribute",
+		"example": "Goa example",
+		"add": "Goa add",
+		"sub": "Goa sub",
+		"response": "Goa response",
+		"error-response": "Goa error response",
+		"missing": "{field} is missing"
 	}
 }`), &messages)
 
@@ -0,0 +1,42 @@
+// Package vary implements the merging of the Vary response header shared by
+// the middlewares of the plugins.
+package vary
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Add adds the given header names to the Vary header of h unless they are
+// already listed. Add preserves the existing values so that the Vary
+// header set by other handlers or middlewares is not overwritten.
+func Add(h http.Header, names ...string) {
+	var existing []string
+	for _, v := range h.Values("Vary") {
+		for _, n := range strings.Split(v, ",") {
+			n = strings.TrimSpace(n)
+			if n == "*" {
+				// Response varies on everything already
+				return
+			}
+			existing = append(existing, n)
+		}
+	}
+	var missing []string
+	for _, n := range names {
+		found := false
+		for _, e := range existing {
+			if strings.EqualFold(e, n) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, n)
+			missing = append(missing, n)
+		}
+	}
+	if len(missing) > 0 {
+		h.Add("Vary", strings.Join(missing, ", "))
+	}
+}